@@ -0,0 +1,347 @@
+package flaeg
+
+import (
+	"bytes"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"os"
+	"reflect"
+	"sort"
+	"strconv"
+	"strings"
+
+	"github.com/containous/flaeg/parse"
+)
+
+// ErrConfigPrinted is returned by Flaeg.Parse and Flaeg.Run when the flag --print-config, enabled by Flaeg.SetPrintConfig, is called:
+// the configuration was printed, the program should stop.
+var ErrConfigPrinted = errors.New("configuration printed")
+
+// printConfigFlag prints the loaded configuration and exits
+const printConfigFlag = "print-config"
+
+// Formats of DumpConfig
+const (
+	FormatTOML  = "toml"
+	FormatJSON  = "json"
+	FormatYAML  = "yaml"
+	FormatEnv   = "env"
+	FormatFlags = "flags"
+)
+
+// flagValue is the value of a flag in a configuration
+type flagValue struct {
+	name    string
+	value   string
+	raw     bool     // value can be written without quotes (bool or number)
+	pointer bool     // flag on a pointer
+	list    []string // elements of a list flag (isList), written as an array
+	isList  bool
+}
+
+// DumpConfig writes the configuration of cmd in format: FormatTOML, FormatJSON, FormatYAML,
+// FormatEnv (NAME=value lines) or FormatFlags (one --flag=value argument by line).
+// Keys are flags names and values are written by their parsers. Values of secret fields are masked.
+func DumpConfig(w io.Writer, cmd *Command, customParsers map[reflect.Type]parse.Parser, format string) error {
+	values, err := getFlagValues(cmd.Config, customParsers)
+	if err != nil {
+		return err
+	}
+
+	switch strings.ToLower(format) {
+	case FormatTOML:
+		return writeTOML(w, flagValuesTree(values), "")
+	case FormatJSON:
+		return writeJSON(w, flagValuesTree(values))
+	case FormatYAML:
+		return writeYAML(w, flagValuesTree(values), "")
+	case FormatEnv:
+		for _, v := range values {
+			value := v.value
+			if strings.ContainsAny(value, " \t\r\n\"'#$\\") {
+				value = strconv.Quote(value)
+			}
+			if _, err := fmt.Fprintf(w, "%s=%s\n", envName(v.name), value); err != nil {
+				return err
+			}
+		}
+		return nil
+	case FormatFlags:
+		for _, v := range values {
+			if _, err := fmt.Fprintf(w, "--%s=%s\n", v.name, v.value); err != nil {
+				return err
+			}
+		}
+		return nil
+	}
+	return fmt.Errorf("unknown configuration format %q, valid formats are: %s, %s, %s, %s, %s", format, FormatTOML, FormatJSON, FormatYAML, FormatEnv, FormatFlags)
+}
+
+// getFlagValues returns the values of the flags in config, sorted by flag.
+// Flags under nil pointers are omitted.
+func getFlagValues(config interface{}, customParsers map[reflect.Type]parse.Parser) ([]flagValue, error) {
+	parsers, err := parse.LoadParsers(customParsers)
+	if err != nil {
+		return nil, err
+	}
+
+	flagMap := make(map[string]reflect.StructField)
+	if err := getTypesRecursive(reflect.ValueOf(config), flagMap, ""); err != nil {
+		return nil, err
+	}
+
+	valMap := make(map[string]reflect.Value)
+	getValuesRecursive(reflect.ValueOf(config), valMap, "")
+
+	var flags []string
+	for flg := range valMap {
+		flags = append(flags, flg)
+	}
+	sort.Strings(flags)
+
+	var values []flagValue
+	for _, flg := range flags {
+		field := flagMap[flg]
+		parser, err := newFieldParser(field, parsers)
+		if err == ErrParserNotFound {
+			continue
+		}
+		if err != nil {
+			return nil, err
+		}
+		setParserValue(parser, valMap[flg])
+
+		value := flagValue{name: flg, value: parser.String(), pointer: valMap[flg].Kind() == reflect.Bool && field.Type.Kind() == reflect.Bool && isPointerFlag(flg, flagMap)}
+		switch parserValue(parser).Kind() {
+		case reflect.Bool, reflect.Int, reflect.Int8, reflect.Int16, reflect.Int32, reflect.Int64,
+			reflect.Uint, reflect.Uint8, reflect.Uint16, reflect.Uint32, reflect.Uint64, reflect.Float32, reflect.Float64:
+			value.raw = json.Valid([]byte(value.value))
+		}
+		if elements, separators, ok := listElements(parser); ok {
+			value.value, value.list, value.isList = parse.JoinList(elements, separators), elements, true
+		}
+		if isSecret(field) {
+			value.value, value.raw, value.list, value.isList = secretMask, false, nil, false
+		}
+		values = append(values, value)
+	}
+	return values, nil
+}
+
+// isPointerFlag returns true if flg has sub-flags, so is a flag on a pointer
+func isPointerFlag(flg string, flagMap map[string]reflect.StructField) bool {
+	for f := range flagMap {
+		if strings.HasPrefix(f, flg+".") {
+			return true
+		}
+	}
+	return false
+}
+
+// getValuesRecursive links in valMap a flag with its value in objValue.
+// Pointers are given as bool values: true if not nil,
+// except the pointers set by value (like *url.URL), omitted if nil.
+func getValuesRecursive(objValue reflect.Value, valMap map[string]reflect.Value, key string) {
+	name := key
+	switch objValue.Kind() {
+	case reflect.Struct:
+		for i := 0; i < objValue.NumField(); i++ {
+			if objValue.Type().Field(i).Anonymous {
+				getValuesRecursive(objValue.Field(i), valMap, name)
+			} else if len(objValue.Type().Field(i).Tag.Get("description")) > 0 {
+				fieldName := objValue.Type().Field(i).Name
+				if tag := objValue.Type().Field(i).Tag.Get("long"); len(tag) > 0 {
+					fieldName = tag
+				}
+
+				if len(key) == 0 {
+					name = strings.ToLower(fieldName)
+				} else {
+					name = key + "." + strings.ToLower(fieldName)
+				}
+
+				if field := objValue.Field(i); field.Kind() != reflect.Ptr || valuePointerTypes[field.Type()] && !field.IsNil() {
+					valMap[name] = field
+				}
+				getValuesRecursive(objValue.Field(i), valMap, name)
+			}
+		}
+	case reflect.Ptr:
+		if valuePointerTypes[objValue.Type()] {
+			return
+		}
+		if len(key) > 0 {
+			valMap[name] = reflect.ValueOf(!objValue.IsNil())
+		}
+		if !objValue.IsNil() {
+			getValuesRecursive(objValue.Elem(), valMap, name)
+		}
+	}
+}
+
+// envName returns the environment variable name of a flag: db.ip gives DB_IP
+func envName(flg string) string {
+	return strings.ToUpper(strings.NewReplacer(".", "_", "-", "_").Replace(flg))
+}
+
+// flagValuesTree nests the values following the flags paths.
+// Pointer flags are tables, omitted if the pointer is nil.
+func flagValuesTree(values []flagValue) map[string]interface{} {
+	tree := map[string]interface{}{}
+	for _, v := range values {
+		if v.pointer && v.value != "true" {
+			continue
+		}
+
+		table := tree
+		path := strings.Split(v.name, ".")
+		for _, key := range path[:len(path)-1] {
+			sub, ok := table[key].(map[string]interface{})
+			if !ok {
+				sub = map[string]interface{}{}
+				table[key] = sub
+			}
+			table = sub
+		}
+
+		key := path[len(path)-1]
+		switch {
+		case v.pointer:
+			if _, ok := table[key].(map[string]interface{}); !ok {
+				table[key] = map[string]interface{}{}
+			}
+		case v.isList:
+			table[key] = append([]string{}, v.list...)
+		case v.raw:
+			table[key] = json.RawMessage(v.value)
+		default:
+			table[key] = v.value
+		}
+	}
+	return tree
+}
+
+// sortedKeys returns the keys of table, values first then tables
+func sortedKeys(table map[string]interface{}) []string {
+	var keys []string
+	for key := range table {
+		keys = append(keys, key)
+	}
+	sort.Slice(keys, func(i, j int) bool {
+		_, iTable := table[keys[i]].(map[string]interface{})
+		_, jTable := table[keys[j]].(map[string]interface{})
+		if iTable != jTable {
+			return jTable
+		}
+		return keys[i] < keys[j]
+	})
+	return keys
+}
+
+// formatValue writes a value of the tree: a quoted string, a raw value or an array of quoted strings
+func formatValue(value interface{}) string {
+	switch v := value.(type) {
+	case json.RawMessage:
+		return string(v)
+	case []string:
+		elements := make([]string, len(v))
+		for i, elem := range v {
+			elements[i] = strconv.Quote(elem)
+		}
+		return "[" + strings.Join(elements, ", ") + "]"
+	}
+	return strconv.Quote(value.(string))
+}
+
+func writeJSON(w io.Writer, tree map[string]interface{}) error {
+	var buf bytes.Buffer
+	encoder := json.NewEncoder(&buf)
+	encoder.SetEscapeHTML(false)
+	encoder.SetIndent("", "  ")
+	if err := encoder.Encode(tree); err != nil {
+		return err
+	}
+	_, err := buf.WriteTo(w)
+	return err
+}
+
+func writeYAML(w io.Writer, table map[string]interface{}, indent string) error {
+	for _, key := range sortedKeys(table) {
+		var err error
+		if sub, ok := table[key].(map[string]interface{}); ok {
+			if len(sub) == 0 {
+				_, err = fmt.Fprintf(w, "%s%s: {}\n", indent, key)
+			} else if _, err = fmt.Fprintf(w, "%s%s:\n", indent, key); err == nil {
+				err = writeYAML(w, sub, indent+"  ")
+			}
+		} else {
+			_, err = fmt.Fprintf(w, "%s%s: %s\n", indent, key, formatValue(table[key]))
+		}
+		if err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+func writeTOML(w io.Writer, table map[string]interface{}, path string) error {
+	for _, key := range sortedKeys(table) {
+		sub, ok := table[key].(map[string]interface{})
+		if !ok {
+			if _, err := fmt.Fprintf(w, "%s = %s\n", key, formatValue(table[key])); err != nil {
+				return err
+			}
+			continue
+		}
+
+		subPath := key
+		if len(path) > 0 {
+			subPath = path + "." + key
+		}
+		if _, err := fmt.Fprintf(w, "\n[%s]\n", subPath); err != nil {
+			return err
+		}
+		if err := writeTOML(w, sub, subPath); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// SetOutput sets the writer of the configuration printed by --print-config (os.Stdout by default).
+func (f *Flaeg) SetOutput(output io.Writer) {
+	f.output = output
+}
+
+// printConfigOutput returns the writer of the configuration printed by --print-config, nil if the flag is disabled
+func (f *Flaeg) printConfigOutput() io.Writer {
+	if !f.printConfig {
+		return nil
+	}
+	if f.output == nil {
+		return os.Stdout
+	}
+	return f.output
+}
+
+// extractPrintConfigFlag removes the flag --print-config[=format] from args,
+// and returns its format (FormatTOML by default)
+func extractPrintConfigFlag(args []string) ([]string, string, bool) {
+	for i, arg := range args {
+		if arg == "--" {
+			break
+		}
+
+		lowerArg := argToLower(arg)
+		if lowerArg == "--"+printConfigFlag || strings.HasPrefix(lowerArg, "--"+printConfigFlag+"=") {
+			format := FormatTOML
+			if index := strings.Index(arg, "="); index != -1 {
+				format = arg[index+1:]
+			}
+			return append(append([]string{}, args[:i]...), args[i+1:]...), format, true
+		}
+	}
+	return args, "", false
+}
@@ -0,0 +1,332 @@
+package flaeg
+
+import (
+	"bytes"
+	"encoding/json"
+	"io/ioutil"
+	"os"
+	"reflect"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/containous/flaeg/parse"
+)
+
+type dumpServer struct {
+	Host string `description:"Server host"`
+	Port uint16 `description:"Server port"`
+}
+
+type dumpConfiguration struct {
+	LogLevel string        `short:"l" description:"Log level"`
+	Timeout  time.Duration `description:"Timeout duration"`
+	Debug    bool          `description:"Debug mode"`
+	Rate     float64       `description:"Rate"`
+	Password string        `secret:"true" description:"Password"`
+	Server   *dumpServer   `description:"Enable server"`
+	Backup   *dumpServer   `description:"Enable backup server"`
+	Ignored  string
+}
+
+func newDumpCommand() *Command {
+	return &Command{
+		Name: "dump",
+		Config: &dumpConfiguration{
+			LogLevel: "INFO",
+			Timeout:  time.Second,
+			Rate:     0.5,
+			Password: "s3cr3t",
+			Server:   &dumpServer{Host: "localhost", Port: 8080},
+			Ignored:  "ignored",
+		},
+		DefaultPointersConfig: &dumpConfiguration{Server: &dumpServer{}, Backup: &dumpServer{}},
+	}
+}
+
+func TestDumpConfig(t *testing.T) {
+	testCases := []struct {
+		format   string
+		expected string
+	}{
+		{
+			format: FormatTOML,
+			expected: `debug = false
+loglevel = "INFO"
+password = "******"
+rate = 0.5
+timeout = "1s"
+
+[server]
+host = "localhost"
+port = 8080
+`,
+		},
+		{
+			format: FormatYAML,
+			expected: `debug: false
+loglevel: "INFO"
+password: "******"
+rate: 0.5
+timeout: "1s"
+server:
+  host: "localhost"
+  port: 8080
+`,
+		},
+		{
+			format: FormatEnv,
+			expected: `BACKUP=false
+DEBUG=false
+LOGLEVEL=INFO
+PASSWORD=******
+RATE=0.5
+SERVER=true
+SERVER_HOST=localhost
+SERVER_PORT=8080
+TIMEOUT=1s
+`,
+		},
+		{
+			format: FormatFlags,
+			expected: `--backup=false
+--debug=false
+--loglevel=INFO
+--password=******
+--rate=0.5
+--server=true
+--server.host=localhost
+--server.port=8080
+--timeout=1s
+`,
+		},
+	}
+
+	for _, test := range testCases {
+		t.Run(test.format, func(t *testing.T) {
+			var buf bytes.Buffer
+			if err := DumpConfig(&buf, newDumpCommand(), nil, test.format); err != nil {
+				t.Fatal(err)
+			}
+
+			if buf.String() != test.expected {
+				t.Errorf("expected\n%s\ngot\n%s", test.expected, buf.String())
+			}
+		})
+	}
+}
+
+func TestDumpConfigJSON(t *testing.T) {
+	var buf bytes.Buffer
+	if err := DumpConfig(&buf, newDumpCommand(), nil, "JSON"); err != nil {
+		t.Fatal(err)
+	}
+
+	var got map[string]interface{}
+	if err := json.Unmarshal(buf.Bytes(), &got); err != nil {
+		t.Fatalf("invalid JSON %v:\n%s", err, buf.String())
+	}
+	check := map[string]interface{}{
+		"debug":    false,
+		"loglevel": "INFO",
+		"password": "******",
+		"rate":     0.5,
+		"timeout":  "1s",
+		"server":   map[string]interface{}{"host": "localhost", "port": float64(8080)},
+	}
+	if !reflect.DeepEqual(got, check) {
+		t.Errorf("expected %v\ngot %v", check, got)
+	}
+}
+
+type dumpListsConfiguration struct {
+	Hosts parse.SliceStrings `description:"Hosts"`
+	Rules parse.SliceStrings `sep:"|" description:"Rules"`
+}
+
+func TestDumpConfigLists(t *testing.T) {
+	config := &dumpListsConfiguration{
+		Hosts: parse.SliceStrings{"a,b", "c"},
+		Rules: parse.SliceStrings{"x y", "^/(v1|v2)"},
+	}
+	cmd := &Command{Name: "dump", Config: config, DefaultPointersConfig: &dumpListsConfiguration{}}
+	customParsers := map[reflect.Type]parse.Parser{
+		reflect.TypeOf(parse.SliceStrings{}): &parse.SliceStrings{},
+	}
+
+	testCases := []struct {
+		format   string
+		expected string
+	}{
+		{
+			format: FormatTOML,
+			expected: `hosts = ["a,b", "c"]
+rules = ["x y", "^/(v1|v2)"]
+`,
+		},
+		{
+			format: FormatYAML,
+			expected: `hosts: ["a,b", "c"]
+rules: ["x y", "^/(v1|v2)"]
+`,
+		},
+		{
+			format: FormatJSON,
+			expected: `{
+  "hosts": [
+    "a,b",
+    "c"
+  ],
+  "rules": [
+    "x y",
+    "^/(v1|v2)"
+  ]
+}
+`,
+		},
+		{
+			format: FormatFlags,
+			expected: `--hosts="a,b",c
+--rules=x y|"^/(v1|v2)"
+`,
+		},
+	}
+
+	for _, test := range testCases {
+		t.Run(test.format, func(t *testing.T) {
+			var buf bytes.Buffer
+			if err := DumpConfig(&buf, cmd, customParsers, test.format); err != nil {
+				t.Fatal(err)
+			}
+
+			if buf.String() != test.expected {
+				t.Errorf("expected\n%s\ngot\n%s", test.expected, buf.String())
+			}
+		})
+	}
+
+	// flags are loaded back to the same configuration
+	var buf bytes.Buffer
+	if err := DumpConfig(&buf, cmd, customParsers, FormatFlags); err != nil {
+		t.Fatal(err)
+	}
+	loaded := &dumpListsConfiguration{}
+	if err := LoadWithParsers(loaded, &dumpListsConfiguration{}, strings.Split(strings.TrimSpace(buf.String()), "\n"), customParsers); err != nil {
+		t.Fatal(err)
+	}
+	if !reflect.DeepEqual(loaded, config) {
+		t.Errorf("expected %+v\ngot %+v", config, loaded)
+	}
+}
+
+func TestDumpConfigUnknownFormat(t *testing.T) {
+	err := DumpConfig(ioutil.Discard, newDumpCommand(), nil, "xml")
+	if err == nil || !strings.Contains(err.Error(), `unknown configuration format "xml"`) {
+		t.Errorf("expected error unknown configuration format got %v", err)
+	}
+}
+
+func TestFlaegPrintConfig(t *testing.T) {
+	cmd := newDumpCommand()
+	f := New(cmd, []string{"--print-config=flags", "--backup.port=22", "-l", "DEBUG"})
+	f.SetPrintConfig(true)
+	var output bytes.Buffer
+	f.SetOutput(&output)
+
+	if _, err := f.Parse(cmd); err != ErrConfigPrinted {
+		t.Fatalf("expected error %v got %v", ErrConfigPrinted, err)
+	}
+	for _, line := range []string{"--backup=true\n", "--backup.port=22\n", "--loglevel=DEBUG\n", "--password=******\n"} {
+		if !strings.Contains(output.String(), line) {
+			t.Errorf("expected %q in\n%s", line, output.String())
+		}
+	}
+}
+
+func TestFlaegPrintConfigHelp(t *testing.T) {
+	cmd := newDumpCommand()
+	f := New(cmd, []string{"--unknown"})
+	f.SetPrintConfig(true)
+	var errOutput bytes.Buffer
+	f.SetErrorOutput(&errOutput)
+
+	if code := f.Execute(); code != ExitUsage {
+		t.Errorf("expected exit code %d got %d", ExitUsage, code)
+	}
+	if !strings.Contains(errOutput.String(), "--print-config Print the configuration and exit") {
+		t.Errorf("expected --print-config in help\n%s", errOutput.String())
+	}
+}
+
+func TestFlaegPrintConfigDisabled(t *testing.T) {
+	cmd := newDumpCommand()
+	f := New(cmd, []string{"--print-config"})
+	var errOutput bytes.Buffer
+	f.SetErrorOutput(&errOutput)
+
+	if code := f.Execute(); code != ExitUsage {
+		t.Errorf("expected exit code %d got %d", ExitUsage, code)
+	}
+	if !strings.HasPrefix(errOutput.String(), "Error: unknown flag: --print-config\n") {
+		t.Errorf("expected error unknown flag --print-config got %q", errOutput.String())
+	}
+	if strings.Contains(errOutput.String(), "Print the configuration and exit") {
+		t.Errorf("expected no --print-config in help\n%s", errOutput.String())
+	}
+}
+
+func TestLoadWithCommandNoPrintConfig(t *testing.T) {
+	// catch stdout
+	backupStdout := os.Stdout
+	defer func() {
+		os.Stdout = backupStdout
+	}()
+	_, w, _ := os.Pipe()
+	os.Stdout = w
+
+	err := LoadWithCommand(newDumpCommand(), []string{"--print-config"}, nil, nil)
+	os.Stdout = backupStdout
+	if errClose := w.Close(); errClose != nil {
+		t.Fatal(errClose)
+	}
+
+	if err == nil || err.Error() != "unknown flag: --print-config" {
+		t.Errorf("expected error unknown flag: --print-config got %v", err)
+	}
+}
+
+func TestFlaegPrintConfigOutput(t *testing.T) {
+	cmd := newDumpCommand()
+	f := New(cmd, []string{"--print-config=env", "--loglevel=DEBUG"})
+	f.SetPrintConfig(true)
+	var output bytes.Buffer
+	f.SetOutput(&output)
+
+	if _, err := f.Parse(cmd); err != ErrConfigPrinted {
+		t.Fatalf("expected error %v got %v", ErrConfigPrinted, err)
+	}
+	if !strings.Contains(output.String(), "LOGLEVEL=DEBUG\n") {
+		t.Errorf("expected LOGLEVEL=DEBUG in\n%s", output.String())
+	}
+}
+
+func TestExtractPrintConfigFlag(t *testing.T) {
+	testCases := []struct {
+		args         []string
+		expectedArgs []string
+		format       string
+		found        bool
+	}{
+		{args: []string{"--a", "--print-config"}, expectedArgs: []string{"--a"}, format: FormatTOML, found: true},
+		{args: []string{"--Print-Config=json", "--a"}, expectedArgs: []string{"--a"}, format: FormatJSON, found: true},
+		{args: []string{"--a", "--", "--print-config"}, expectedArgs: []string{"--a", "--", "--print-config"}},
+		{args: []string{"--print-configuration"}, expectedArgs: []string{"--print-configuration"}},
+	}
+
+	for _, test := range testCases {
+		args, format, found := extractPrintConfigFlag(test.args)
+		if !reflect.DeepEqual(args, test.expectedArgs) || format != test.format || found != test.found {
+			t.Errorf("%v: expected %v %q %t got %v %q %t", test.args, test.expectedArgs, test.format, test.found, args, format, found)
+		}
+	}
+}
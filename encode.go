@@ -0,0 +1,185 @@
+package flaeg
+
+import (
+	"fmt"
+	"reflect"
+	"strings"
+
+	"github.com/containous/flaeg/parse"
+)
+
+// EncodeArgs returns the minimal arguments which reproduce config through Load:
+// loading them into a copy of defaultConfig, with defaultPointersConfig as default pointers values,
+// gives config.
+// Only the flags whose values differ from the defaults are given, as --flag=value using the String method of their parsers
+// (lists of strings are joined with their separators, quoting the elements as parse.SplitList),
+// and pointers flags as --flag when none of their sub-flags is given.
+// Values of secret fields are given in clear.
+// Nil pointers which are not nil in defaultConfig cannot be given by flags and return an error,
+// as values which do not load back unchanged from their flags (a time written with a layout dropping a part of it for instance).
+func EncodeArgs(config interface{}, defaultConfig interface{}, defaultPointersConfig interface{}, customParsers map[reflect.Type]parse.Parser) ([]string, error) {
+	if reflect.TypeOf(config) != reflect.TypeOf(defaultConfig) {
+		return nil, fmt.Errorf("parameters config and defaultConfig must be the same struct. config type: %s is not defaultConfig type: %s", reflect.TypeOf(config), reflect.TypeOf(defaultConfig))
+	}
+
+	parsers, err := parse.LoadParsers(customParsers)
+	if err != nil {
+		return nil, err
+	}
+
+	flagMap := make(map[string]reflect.StructField)
+	if err := getTypesRecursive(reflect.ValueOf(config), flagMap, ""); err != nil {
+		return nil, err
+	}
+	defaultValMap := make(map[string]reflect.Value)
+	if err := getDefaultValue(reflect.ValueOf(defaultConfig), reflect.ValueOf(defaultPointersConfig), defaultValMap, ""); err != nil {
+		return nil, err
+	}
+
+	e := argsEncoder{flagMap: flagMap, defaultValMap: defaultValMap, parsers: parsers}
+	if err := e.encode(reflect.ValueOf(config), reflect.ValueOf(defaultConfig), ""); err != nil {
+		return nil, err
+	}
+	return e.args, nil
+}
+
+// argsEncoder collects the arguments setting the flags of a configuration
+type argsEncoder struct {
+	flagMap       map[string]reflect.StructField
+	defaultValMap map[string]reflect.Value
+	parsers       map[reflect.Type]parse.Parser
+	args          []string
+}
+
+// encode adds the arguments setting objValue, given the value Load would set without them: defValue
+func (e *argsEncoder) encode(objValue reflect.Value, defValue reflect.Value, key string) error {
+	name := key
+	switch objValue.Kind() {
+	case reflect.Struct:
+		for i := 0; i < objValue.NumField(); i++ {
+			if objValue.Type().Field(i).Anonymous {
+				if err := e.encode(objValue.Field(i), defValue.Field(i), name); err != nil {
+					return err
+				}
+			} else if len(objValue.Type().Field(i).Tag.Get("description")) > 0 {
+				fieldName := objValue.Type().Field(i).Name
+				if tag := objValue.Type().Field(i).Tag.Get("long"); len(tag) > 0 {
+					fieldName = tag
+				}
+
+				if len(key) == 0 {
+					name = strings.ToLower(fieldName)
+				} else {
+					name = key + "." + strings.ToLower(fieldName)
+				}
+
+				if objValue.Field(i).Kind() == reflect.Ptr && !valuePointerTypes[objValue.Field(i).Type()] {
+					if err := e.encode(objValue.Field(i), defValue.Field(i), name); err != nil {
+						return err
+					}
+				} else if err := e.encodeField(objValue.Field(i), defValue.Field(i), name); err != nil {
+					return err
+				}
+			}
+		}
+
+	case reflect.Ptr:
+		if len(key) == 0 {
+			return e.encode(objValue.Elem(), defValue.Elem(), name)
+		}
+
+		switch {
+		case objValue.IsNil() && defValue.IsNil():
+			return nil
+		case objValue.IsNil():
+			return fmt.Errorf("flag %s: nil pointer cannot be set by flags, it is not nil by default", name)
+		case defValue.IsNil():
+			// the flag sets the default pointer value
+			defValue = e.defaultValMap[name]
+			nbArgs := len(e.args)
+			if objValue.Elem().Kind() == reflect.Struct {
+				if err := e.encode(objValue.Elem(), defValue.Elem(), name); err != nil {
+					return err
+				}
+			} else if !reflect.DeepEqual(objValue.Elem().Interface(), defValue.Elem().Interface()) {
+				return fmt.Errorf("flag %s: value pointed cannot be set by flags, it is not the default pointer value", name)
+			}
+			if len(e.args) == nbArgs {
+				e.args = append(e.args, "--"+name)
+			}
+		case objValue.Elem().Kind() == reflect.Struct:
+			return e.encode(objValue.Elem(), defValue.Elem(), name)
+		case !reflect.DeepEqual(objValue.Elem().Interface(), defValue.Elem().Interface()):
+			return fmt.Errorf("flag %s: value pointed cannot be set by flags", name)
+		}
+	}
+	return nil
+}
+
+// encodeField adds the argument setting the flag of a field, if its value differs from defValue
+func (e *argsEncoder) encodeField(fieldValue reflect.Value, defValue reflect.Value, name string) error {
+	if valuePointerTypes[fieldValue.Type()] && fieldValue.IsNil() && !defValue.IsNil() {
+		return fmt.Errorf("flag %s: nil pointer cannot be set by flags, it is not nil by default", name)
+	}
+
+	parser, err := newFieldParser(e.flagMap[name], e.parsers)
+	if err == ErrParserNotFound {
+		if fieldValue.Kind() == reflect.Struct {
+			return e.encode(fieldValue, defValue, name)
+		}
+		if !reflect.DeepEqual(fieldValue.Interface(), defValue.Interface()) {
+			return &MissingParserError{Flag: name, Type: fieldValue.Type()}
+		}
+		return nil
+	}
+	if err != nil {
+		return err
+	}
+
+	setParserValue(parser, fieldValue)
+	value := encodeValue(parser)
+
+	setParserValue(parser, defValue)
+	if value == encodeValue(parser) {
+		return nil
+	}
+
+	if e.flagMap[name].Tag.Get("fromfile") == "true" && strings.HasPrefix(value, "@") {
+		value = "@" + value
+	}
+
+	// the value must give the field value back: a layout can drop a part of a time for instance
+	setParserValue(parser, fieldValue)
+	loaded, err := newFieldParser(e.flagMap[name], e.parsers)
+	if err != nil {
+		return err
+	}
+	if err := loaded.Set(value); err != nil {
+		return fmt.Errorf("flag %s: %v", name, err)
+	}
+	if secret, ok := loaded.(*secretParser); ok && secret.err != nil {
+		return fmt.Errorf("flag %s: %v", name, secret.err)
+	}
+	if !reflect.DeepEqual(parserValue(loaded).Interface(), parserValue(parser).Interface()) {
+		return fmt.Errorf("flag %s: value cannot be given exactly by flags, %s loads another value", name, encodedValue(e.flagMap[name], value))
+	}
+	e.args = append(e.args, "--"+name+"="+value)
+	return nil
+}
+
+// encodedValue returns the value given to the flag of structField as shown in errors: masked for secret fields
+func encodedValue(structField reflect.StructField, value string) string {
+	if isSecret(structField) {
+		return secretMask
+	}
+	return fmt.Sprintf("%q", value)
+}
+
+// encodeValue returns the value held by parser as given to its flag:
+// lists of strings are joined with their separators, other values are written by the parser.
+func encodeValue(parser parse.Parser) string {
+	if elements, separators, ok := listElements(parser); ok {
+		return parse.JoinList(elements, separators)
+	}
+	return parser.String()
+}
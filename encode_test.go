@@ -0,0 +1,160 @@
+package flaeg
+
+import (
+	"reflect"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/containous/flaeg/parse"
+)
+
+type encodeConfiguration struct {
+	LogLevel    string             `short:"l" description:"Log level"`
+	Timeout     time.Duration      `description:"Timeout duration"`
+	Debug       bool               `description:"Debug mode"`
+	Password    string             `secret:"true" description:"Password"`
+	Certificate string             `fromfile:"true" description:"Certificate"`
+	Hosts       parse.SliceStrings `description:"Hosts"`
+	Rules       parse.SliceStrings `sep:"|" description:"Rules"`
+	Server      *dumpServer        `description:"Enable server"`
+	Backup      *dumpServer        `description:"Enable backup server"`
+	Proxy       *dumpServer        `description:"Enable proxy"`
+}
+
+func newEncodeDefaultConfig() *encodeConfiguration {
+	return &encodeConfiguration{
+		LogLevel: "INFO",
+		Timeout:  time.Second,
+		Server:   &dumpServer{Host: "localhost", Port: 80},
+	}
+}
+
+func newEncodeDefaultPointersConfig() *encodeConfiguration {
+	return &encodeConfiguration{
+		Server: &dumpServer{Host: "0.0.0.0", Port: 8080},
+		Backup: &dumpServer{Host: "backup", Port: 8080},
+		Proxy:  &dumpServer{Host: "proxy", Port: 3128},
+	}
+}
+
+func TestEncodeArgs(t *testing.T) {
+	testCases := []struct {
+		desc     string
+		config   *encodeConfiguration
+		expected []string
+	}{
+		{
+			desc:   "defaults",
+			config: newEncodeDefaultConfig(),
+		},
+		{
+			desc: "values",
+			config: &encodeConfiguration{
+				LogLevel:    "DEBUG",
+				Timeout:     90 * time.Second,
+				Debug:       true,
+				Password:    "s3cr3t",
+				Certificate: "@cert",
+				Server:      &dumpServer{Host: "localhost", Port: 443},
+			},
+			expected: []string{"--loglevel=DEBUG", "--timeout=1m30s", "--debug=true", "--password=s3cr3t", "--certificate=@@cert", "--server.port=443"},
+		},
+		{
+			desc: "pointers",
+			config: &encodeConfiguration{
+				LogLevel: "INFO",
+				Timeout:  time.Second,
+				Server:   &dumpServer{Host: "localhost", Port: 80},
+				Backup:   &dumpServer{Host: "backup", Port: 8080},
+				Proxy:    &dumpServer{Host: "squid", Port: 3128},
+			},
+			expected: []string{"--backup", "--proxy.host=squid"},
+		},
+		{
+			desc: "lists",
+			config: &encodeConfiguration{
+				LogLevel: "INFO",
+				Timeout:  time.Second,
+				Hosts:    parse.SliceStrings{"a,b", "c", "it's", ""},
+				Rules:    parse.SliceStrings{"x y", "^/(v1|v2)", `"quoted"`},
+				Server:   &dumpServer{Host: "localhost", Port: 80},
+			},
+			expected: []string{`--hosts="a,b",c,it's,""`, `--rules=x y|"^/(v1|v2)"|"\"quoted\""`},
+		},
+	}
+	customParsers := map[reflect.Type]parse.Parser{
+		reflect.TypeOf(parse.SliceStrings{}): &parse.SliceStrings{},
+	}
+
+	for _, test := range testCases {
+		t.Run(test.desc, func(t *testing.T) {
+			args, err := EncodeArgs(test.config, newEncodeDefaultConfig(), newEncodeDefaultPointersConfig(), customParsers)
+			if err != nil {
+				t.Fatal(err)
+			}
+			if !reflect.DeepEqual(args, test.expected) {
+				t.Errorf("expected %q got %q", test.expected, args)
+			}
+
+			config := newEncodeDefaultConfig()
+			if err := LoadWithParsers(config, newEncodeDefaultPointersConfig(), args, customParsers); err != nil {
+				t.Fatal(err)
+			}
+			if !reflect.DeepEqual(config, test.config) {
+				t.Errorf("expected %+v\ngot %+v", test.config, config)
+			}
+		})
+	}
+}
+
+func TestEncodeArgsNilPointer(t *testing.T) {
+	config := newEncodeDefaultConfig()
+	config.Server = nil
+
+	_, err := EncodeArgs(config, newEncodeDefaultConfig(), newEncodeDefaultPointersConfig(), nil)
+	if err == nil || !strings.Contains(err.Error(), "flag server: nil pointer") {
+		t.Errorf("expected error nil pointer on flag server got %v", err)
+	}
+}
+
+func TestEncodeArgsDifferentTypes(t *testing.T) {
+	_, err := EncodeArgs(newEncodeDefaultConfig(), &dumpConfiguration{}, &dumpConfiguration{}, nil)
+	if err == nil || !strings.Contains(err.Error(), "must be the same struct") {
+		t.Errorf("expected error must be the same struct got %v", err)
+	}
+}
+
+func TestEncodeArgsLostValue(t *testing.T) {
+	type timeConfiguration struct {
+		Day   time.Time `description:"Day" layout:"2006-01-02" tz:"Europe/Paris"`
+		Start time.Time `description:"Start time"`
+	}
+
+	testCases := []struct {
+		desc   string
+		config *timeConfiguration
+		flag   string
+	}{
+		{
+			desc:   "time out of its layout",
+			config: &timeConfiguration{Day: time.Date(2020, 1, 2, 3, 4, 5, 0, time.UTC)},
+			flag:   "day",
+		},
+		{
+			desc:   "time with sub-seconds",
+			config: &timeConfiguration{Start: time.Date(2020, 1, 2, 3, 4, 5, 6, time.UTC)},
+			flag:   "start",
+		},
+	}
+
+	for _, test := range testCases {
+		test := test
+		t.Run(test.desc, func(t *testing.T) {
+			_, err := EncodeArgs(test.config, &timeConfiguration{}, &timeConfiguration{}, nil)
+			if err == nil || !strings.Contains(err.Error(), "flag "+test.flag+": value cannot be given exactly by flags") {
+				t.Errorf("expected error value cannot be given exactly on flag %s got %v", test.flag, err)
+			}
+		})
+	}
+}
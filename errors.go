@@ -0,0 +1,171 @@
+package flaeg
+
+import (
+	"flag"
+	"fmt"
+	"reflect"
+	"sort"
+	"strconv"
+	"strings"
+
+	"github.com/containous/flaeg/parse"
+)
+
+// UnknownFlagError is returned when an argument calls a flag which does not exist
+type UnknownFlagError struct {
+	Flag        string   // flag name, without dashes
+	Shorthand   bool     // Flag is a shorthand flag
+	Suggestions []string // existing flags close to Flag
+}
+
+func (e *UnknownFlagError) Error() string {
+	if e.Shorthand {
+		return fmt.Sprintf("unknown shorthand flag: -%s", e.Flag)
+	}
+	return fmt.Sprintf("unknown flag: --%s%s", e.Flag, didYouMean("--", e.Suggestions))
+}
+
+// InvalidValueError is returned when the value given to a flag cannot be parsed.
+// Value is masked for secret fields.
+type InvalidValueError struct {
+	Flag  string
+	Value string
+	Type  reflect.Type // type of the field
+	Err   error        // error of the parser
+}
+
+func (e *InvalidValueError) Error() string {
+	return fmt.Sprintf("invalid argument %q for --%s: %v", e.Value, e.Flag, e.Err)
+}
+
+// Unwrap returns the error of the parser.
+func (e *InvalidValueError) Unwrap() error { return e.Err }
+
+// MissingParserError is returned when no parser matches the type of a flagged field.
+// It matches ErrParserNotFound with errors.Is.
+type MissingParserError struct {
+	Flag string
+	Type reflect.Type
+}
+
+func (e *MissingParserError) Error() string {
+	return fmt.Sprintf("%v: flag --%s of type %s", ErrParserNotFound, e.Flag, e.Type)
+}
+
+// Is returns true for ErrParserNotFound.
+func (e *MissingParserError) Is(target error) bool { return target == ErrParserNotFound }
+
+// MissingParsersError lists the flagged fields without parser, sorted by flag.
+// It matches ErrParserNotFound with errors.Is, and its first MissingParserError with errors.As.
+type MissingParsersError struct {
+	Errors []*MissingParserError
+}
+
+func (e *MissingParsersError) Error() string {
+	var flags []string
+	for _, err := range e.Errors {
+		flags = append(flags, fmt.Sprintf("--%s (%s)", err.Flag, err.Type))
+	}
+	return fmt.Sprintf("%v: %s", ErrParserNotFound, strings.Join(flags, ", "))
+}
+
+// Is returns true for ErrParserNotFound.
+func (e *MissingParsersError) Is(target error) bool { return target == ErrParserNotFound }
+
+// As sets target to the first MissingParserError if target is a **MissingParserError.
+func (e *MissingParsersError) As(target interface{}) bool {
+	if missingParser, ok := target.(**MissingParserError); ok && len(e.Errors) > 0 {
+		*missingParser = e.Errors[0]
+		return true
+	}
+	return false
+}
+
+// newMissingParsersError returns a MissingParsersError on errs, sorted by flag
+func newMissingParsersError(errs []*MissingParserError) *MissingParsersError {
+	sort.Slice(errs, func(i, j int) bool { return errs[i].Flag < errs[j].Flag })
+	return &MissingParsersError{Errors: errs}
+}
+
+// DuplicateFlagError is returned when two fields of a configuration have the same flag
+type DuplicateFlagError struct {
+	Flag string
+}
+
+func (e *DuplicateFlagError) Error() string {
+	return fmt.Sprintf("tag already exists: %s", e.Flag)
+}
+
+// UnknownCommandError is returned when the command called does not exist
+type UnknownCommandError struct {
+	Command     string
+	Suggestions []string // existing commands close to Command
+}
+
+func (e *UnknownCommandError) Error() string {
+	return fmt.Sprintf("command %s not found%s", e.Command, didYouMean("", e.Suggestions))
+}
+
+// AmbiguousFlagError is returned when a flag is given by a prefix of many flags, with prefix matching
+type AmbiguousFlagError struct {
+	Flag       string   // prefix given, without dashes
+	Candidates []string // flags starting with Flag
+}
+
+func (e *AmbiguousFlagError) Error() string {
+	return fmt.Sprintf("ambiguous flag --%s, could be --%s", e.Flag, strings.Join(e.Candidates, " or --"))
+}
+
+// AmbiguousCommandError is returned when a command is given by a prefix of many commands, with prefix matching
+type AmbiguousCommandError struct {
+	Command    string   // prefix given
+	Candidates []string // commands starting with Command
+}
+
+func (e *AmbiguousCommandError) Error() string {
+	return fmt.Sprintf("ambiguous command %s, could be %s", e.Command, strings.Join(e.Candidates, " or "))
+}
+
+// flagSetError returns the typed error of an error returned by the flag parser.
+// Unknown flags come with suggestions taken from flags.
+func flagSetError(err error, flags []string) error {
+	msg := err.Error()
+	switch {
+	case strings.HasPrefix(msg, "unknown flag: --"):
+		flg := strings.TrimPrefix(msg, "unknown flag: --")
+		return &UnknownFlagError{Flag: flg, Suggestions: suggest(flg, flags)}
+	case strings.HasPrefix(msg, "unknown shorthand flag: "):
+		// unknown shorthand flag: 'x' in -xyz
+		quoted := strings.TrimPrefix(msg, "unknown shorthand flag: ")
+		if index := strings.LastIndex(quoted, "' in -"); index != -1 {
+			if shorthand, errQuote := strconv.Unquote(quoted[:index+1]); errQuote == nil {
+				return &UnknownFlagError{Flag: shorthand, Shorthand: true}
+			}
+		}
+	}
+	return err
+}
+
+// invalidValueRecorder keeps the error of the last value a parser failed to set,
+// as the flag parser only returns it formatted
+type invalidValueRecorder struct {
+	flag.Value
+	flag   string
+	typ    reflect.Type
+	failed **InvalidValueError
+}
+
+// Set sets the value, and records the error if it fails.
+func (r *invalidValueRecorder) Set(s string) error {
+	err := r.Value.Set(s)
+	if err != nil {
+		*r.failed = &InvalidValueError{Flag: r.flag, Value: s, Type: r.typ, Err: err}
+	}
+	return err
+}
+
+// IsBoolFlag returns true if the parser is a boolean flag
+func (r *invalidValueRecorder) IsBoolFlag() bool {
+	parser, ok := r.Value.(parse.Parser)
+	return ok && isBoolFlag(parser)
+}
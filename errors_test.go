@@ -0,0 +1,205 @@
+package flaeg
+
+import (
+	"errors"
+	"reflect"
+	"strconv"
+	"testing"
+
+	"github.com/containous/flaeg/parse"
+)
+
+type errorsConfiguration struct {
+	LogLevel string       `short:"l" description:"Log level"`
+	Port     int          `short:"p" description:"Port"`
+	PIN      int          `secret:"true" description:"PIN code"`
+	Ratio    complex64    `description:"Ratio"`
+	Servers  []ServerInfo `description:"Servers"`
+}
+
+func parseErrorsConfiguration(t *testing.T, args []string) error {
+	t.Helper()
+
+	flagMap := make(map[string]reflect.StructField)
+	if err := getTypesRecursive(reflect.ValueOf(&errorsConfiguration{}), flagMap, ""); err != nil {
+		t.Fatal(err)
+	}
+	parsers, err := parse.LoadParsers(nil)
+	if err != nil {
+		t.Fatal(err)
+	}
+	_, err = parseArgs(args, flagMap, parsers)
+	return err
+}
+
+func TestUnknownFlagError(t *testing.T) {
+	testCases := []struct {
+		args     []string
+		expected UnknownFlagError
+	}{
+		{args: []string{"--port=80", "--unknown=1"}, expected: UnknownFlagError{Flag: "unknown"}},
+		{args: []string{"-x"}, expected: UnknownFlagError{Flag: "x", Shorthand: true}},
+		{args: []string{"--logleve=DEBUG"}, expected: UnknownFlagError{Flag: "logleve", Suggestions: []string{"loglevel"}}},
+		{args: []string{"--pni=1234"}, expected: UnknownFlagError{Flag: "pni", Suggestions: []string{"pin"}}},
+	}
+
+	for _, test := range testCases {
+		err := parseErrorsConfiguration(t, test.args)
+
+		var unknownFlag *UnknownFlagError
+		if !errors.As(err, &unknownFlag) {
+			t.Fatalf("%v: expected UnknownFlagError got %#v", test.args, err)
+		}
+		if !reflect.DeepEqual(*unknownFlag, test.expected) {
+			t.Errorf("%v: expected %+v got %+v", test.args, test.expected, *unknownFlag)
+		}
+	}
+}
+
+func TestInvalidValueError(t *testing.T) {
+	testCases := []struct {
+		args     []string
+		expected InvalidValueError
+	}{
+		{args: []string{"--port=80a"}, expected: InvalidValueError{Flag: "port", Value: "80a", Type: reflect.TypeOf(0)}},
+		{args: []string{"-p", "80a"}, expected: InvalidValueError{Flag: "port", Value: "80a", Type: reflect.TypeOf(0)}},
+		{args: []string{"--pin=12a4"}, expected: InvalidValueError{Flag: "pin", Value: secretMask, Type: reflect.TypeOf(0)}},
+	}
+
+	for _, test := range testCases {
+		err := parseErrorsConfiguration(t, test.args)
+
+		var invalidValue *InvalidValueError
+		if !errors.As(err, &invalidValue) {
+			t.Fatalf("%v: expected InvalidValueError got %#v", test.args, err)
+		}
+		if invalidValue.Flag != test.expected.Flag || invalidValue.Value != test.expected.Value || invalidValue.Type != test.expected.Type {
+			t.Errorf("%v: expected %+v got %+v", test.args, test.expected, *invalidValue)
+		}
+		if invalidValue.Err == nil || errors.Unwrap(err) != invalidValue.Err {
+			t.Errorf("%v: expected the error of the parser got %v", test.args, errors.Unwrap(err))
+		}
+	}
+}
+
+func TestInvalidValueErrorUnwrap(t *testing.T) {
+	err := parseErrorsConfiguration(t, []string{"--port=99999999999999999999"})
+
+	var numErr *strconv.NumError
+	if !errors.As(err, &numErr) || !errors.Is(err, strconv.ErrRange) {
+		t.Errorf("expected a strconv range error got %#v", err)
+	}
+}
+
+func TestMissingParserError(t *testing.T) {
+	err := parseErrorsConfiguration(t, []string{"--port=80"})
+
+	if !errors.Is(err, ErrParserNotFound) {
+		t.Errorf("expected error %v got %v", ErrParserNotFound, err)
+	}
+	var missingParser *MissingParserError
+	if !errors.As(err, &missingParser) {
+		t.Fatalf("expected MissingParserError got %#v", err)
+	}
+	if missingParser.Flag != "ratio" || missingParser.Type != reflect.TypeOf(complex64(0)) {
+		t.Errorf("expected flag ratio of type complex64 got %+v", *missingParser)
+	}
+}
+
+func TestDuplicateFlagError(t *testing.T) {
+	config := &struct {
+		Name  string `description:"Name"`
+		Other string `long:"name" description:"Other name"`
+	}{}
+
+	err := getTypesRecursive(reflect.ValueOf(config), map[string]reflect.StructField{}, "")
+
+	var duplicateFlag *DuplicateFlagError
+	if !errors.As(err, &duplicateFlag) || duplicateFlag.Flag != "name" {
+		t.Errorf("expected DuplicateFlagError on flag name got %#v", err)
+	}
+}
+
+func TestUnknownCommandError(t *testing.T) {
+	rootCmd := &Command{Name: "root", Config: &errorsConfiguration{}, DefaultPointersConfig: &errorsConfiguration{}}
+	testCases := []struct {
+		command     string
+		suggestions []string
+	}{
+		{command: "unknown"},
+		{command: "verison", suggestions: []string{"version"}},
+		{command: "hiden"},
+	}
+
+	for _, test := range testCases {
+		f := New(rootCmd, []string{test.command, "--port=80"})
+		f.AddCommand(&Command{Name: "version", Config: &struct{}{}, DefaultPointersConfig: &struct{}{}})
+		f.AddCommand(&Command{Name: "hidden", Config: &struct{}{}, DefaultPointersConfig: &struct{}{}, HideHelp: true})
+
+		_, err := f.GetCommand()
+
+		var unknownCommand *UnknownCommandError
+		if !errors.As(err, &unknownCommand) || unknownCommand.Command != test.command || !reflect.DeepEqual(unknownCommand.Suggestions, test.suggestions) {
+			t.Errorf("expected UnknownCommandError on command %s with suggestions %v got %#v", test.command, test.suggestions, err)
+		}
+	}
+}
+
+func TestMissingParsersError(t *testing.T) {
+	err := parseErrorsConfiguration(t, nil)
+
+	var missingParsers *MissingParsersError
+	if !errors.As(err, &missingParsers) {
+		t.Fatalf("expected MissingParsersError got %#v", err)
+	}
+	check := []*MissingParserError{
+		{Flag: "ratio", Type: reflect.TypeOf(complex64(0))},
+		{Flag: "servers", Type: reflect.TypeOf([]ServerInfo{})},
+	}
+	if !reflect.DeepEqual(missingParsers.Errors, check) {
+		t.Errorf("expected %v got %v", check, missingParsers.Errors)
+	}
+
+	expected := "parser not found or custom parser missing: --ratio (complex64), --servers ([]flaeg.ServerInfo)"
+	if err.Error() != expected {
+		t.Errorf("expected error %q got %q", expected, err)
+	}
+}
+
+func TestCheckParsers(t *testing.T) {
+	if err := CheckParsers(&errorsConfiguration{}, nil); !errors.Is(err, ErrParserNotFound) {
+		t.Errorf("expected error %v got %v", ErrParserNotFound, err)
+	}
+
+	customParsers := map[reflect.Type]parse.Parser{
+		reflect.TypeOf(complex64(0)):   new(parse.StringValue),
+		reflect.TypeOf([]ServerInfo{}): &sliceServerValue{},
+	}
+	if err := CheckParsers(&errorsConfiguration{}, customParsers); err != nil {
+		t.Errorf("expected no error got %v", err)
+	}
+}
+
+func TestFlaegStrict(t *testing.T) {
+	testCases := []struct {
+		strict       bool
+		expectedPort int
+	}{
+		{strict: false, expectedPort: 80},
+		{strict: true, expectedPort: 0},
+	}
+
+	for _, test := range testCases {
+		config := &errorsConfiguration{}
+		rootCmd := &Command{Name: "root", Config: config, DefaultPointersConfig: &errorsConfiguration{}}
+		f := New(rootCmd, []string{"--port=80"})
+		f.SetStrict(test.strict)
+
+		if _, err := f.Parse(rootCmd); !errors.Is(err, ErrParserNotFound) {
+			t.Errorf("strict %t: expected error %v got %v", test.strict, ErrParserNotFound, err)
+		}
+		if config.Port != test.expectedPort {
+			t.Errorf("strict %t: expected port %d got %d", test.strict, test.expectedPort, config.Port)
+		}
+	}
+}
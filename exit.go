@@ -0,0 +1,116 @@
+package flaeg
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"io"
+	"os"
+	"reflect"
+
+	"github.com/containous/flaeg/parse"
+	flag "github.com/ogier/pflag"
+)
+
+// Exit codes of Flaeg.Execute
+const (
+	ExitOK         = 0 // the command succeeded, or the help or the configuration was printed
+	ExitFailure    = 1 // the Run function of the command failed
+	ExitUsage      = 2 // the command line is invalid: unknown command or flag, invalid value...
+	ExitValidation = 3 // the configuration structure is invalid: missing parser, duplicate flag...
+)
+
+// ExitCoder is implemented by the errors returned by Run functions choosing the exit code of Flaeg.Execute
+type ExitCoder interface {
+	error
+	ExitCode() int
+}
+
+// usageError is an error on the command line already printed with the help
+type usageError struct {
+	error
+}
+
+func (e *usageError) Unwrap() error { return e.error }
+
+// printUsageError prints err and the help of cmd to errOutput, and returns err as usageError.
+// flag.ErrHelp is only printed as help, to os.Stdout, and returned as is.
+// The help lists --print-config if printConfig is true.
+func printUsageError(errOutput io.Writer, printConfig bool, err error, flagMap map[string]reflect.StructField, defaultValMap map[string]reflect.Value, parsers map[reflect.Type]parse.Parser, cmd *Command, subCmd []*Command) error {
+	helpOutput := errOutput
+	if err == flag.ErrHelp {
+		helpOutput = os.Stdout
+	} else {
+		fmt.Fprintf(errOutput, "Error: %s\n", err)
+	}
+
+	if errHelp := printHelpWithCommand(helpOutput, printConfig, flagMap, defaultValMap, parsers, cmd, subCmd); errHelp != nil {
+		err = errHelp
+		fmt.Fprintf(errOutput, "Error: %s\n", err)
+	}
+
+	if err == flag.ErrHelp {
+		return err
+	}
+	return &usageError{err}
+}
+
+// SetErrorOutput sets the writer of the errors printed by Execute and Main (os.Stderr by default).
+func (f *Flaeg) SetErrorOutput(errOutput io.Writer) {
+	f.errOutput = errOutput
+}
+
+func (f *Flaeg) errorOutput() io.Writer {
+	if f.errOutput == nil {
+		return os.Stderr
+	}
+	return f.errOutput
+}
+
+// Execute runs the called command as Run, prints the error if any to the error output,
+// and returns the exit code:
+// ExitOK on success or if the help or the configuration was printed,
+// ExitUsage on errors on the command line, printed with the help,
+// ExitValidation on errors on the configuration structure,
+// and for Run errors the code given by their ExitCode method if they implement ExitCoder, ExitFailure otherwise.
+func (f *Flaeg) Execute() int {
+	errOutput := f.errorOutput()
+
+	cmd, err := f.GetCommand()
+	if err != nil {
+		fmt.Fprintf(errOutput, "Error: %s\n", err)
+		return ExitUsage
+	}
+
+	if _, err := f.parse(cmd, errOutput); err != nil {
+		var usageErr *usageError
+		var ambiguousFlag *AmbiguousFlagError
+		switch {
+		case err == flag.ErrHelp || err == ErrConfigPrinted:
+			return ExitOK
+		case errors.As(err, &usageErr):
+			return ExitUsage
+		case errors.As(err, &ambiguousFlag):
+			fmt.Fprintf(errOutput, "Error: %s\n", err)
+			return ExitUsage
+		}
+		fmt.Fprintf(errOutput, "Error: %s\n", err)
+		return ExitValidation
+	}
+
+	if err := f.run(context.Background(), cmd); err != nil {
+		fmt.Fprintf(errOutput, "Error: %s\n", err)
+
+		var exitCoder ExitCoder
+		if errors.As(err, &exitCoder) {
+			return exitCoder.ExitCode()
+		}
+		return ExitFailure
+	}
+	return ExitOK
+}
+
+// Main runs the called command with Execute, and exits with its exit code.
+func (f *Flaeg) Main() {
+	os.Exit(f.Execute())
+}
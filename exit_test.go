@@ -0,0 +1,143 @@
+package flaeg
+
+import (
+	"bytes"
+	"errors"
+	"fmt"
+	"io/ioutil"
+	"os"
+	"strings"
+	"testing"
+)
+
+type exitError struct {
+	code int
+}
+
+func (e *exitError) Error() string { return fmt.Sprintf("exit %d", e.code) }
+
+func (e *exitError) ExitCode() int { return e.code }
+
+type exitConfiguration struct {
+	Port  int   `description:"Port"`
+	Ratio uint8 `description:"Ratio"`
+}
+
+type exitMissingParserConfiguration struct {
+	Port  int       `description:"Port"`
+	Ratio complex64 `description:"Ratio"`
+}
+
+func TestExecute(t *testing.T) {
+	testCases := []struct {
+		desc           string
+		args           []string
+		config         interface{}
+		runErr         error
+		expectedCode   int
+		expectedOutput string
+	}{
+		{desc: "success", args: []string{"--port=80"}, expectedCode: ExitOK},
+		{desc: "help", args: []string{"--help"}, expectedCode: ExitOK},
+		{desc: "print config", args: []string{"--print-config"}, expectedCode: ExitOK},
+		{desc: "unknown flag", args: []string{"--prot=80"}, expectedCode: ExitUsage, expectedOutput: "Error: unknown flag: --prot, did you mean --port?\n"},
+		{desc: "invalid value", args: []string{"--ratio=256"}, expectedCode: ExitUsage, expectedOutput: `Error: invalid argument "256" for --ratio`},
+		{desc: "unknown command", args: []string{"versoin"}, expectedCode: ExitUsage, expectedOutput: "Error: command versoin not found, did you mean version?\n"},
+		{desc: "missing parser", args: []string{"--port=80"}, config: &exitMissingParserConfiguration{}, expectedCode: ExitValidation, expectedOutput: "Error: parser not found"},
+		{desc: "run failure", runErr: errors.New("boom"), expectedCode: ExitFailure, expectedOutput: "Error: boom\n"},
+		{desc: "exit coder", runErr: &exitError{code: 42}, expectedCode: 42, expectedOutput: "Error: exit 42\n"},
+		{desc: "wrapped exit coder", runErr: fmt.Errorf("failed: %w", &exitError{code: 5}), expectedCode: 5, expectedOutput: "Error: failed: exit 5\n"},
+	}
+
+	for _, test := range testCases {
+		t.Run(test.desc, func(t *testing.T) {
+			// catch stdout
+			backupStdout := os.Stdout
+			defer func() {
+				os.Stdout = backupStdout
+			}()
+			os.Stdout, _ = os.OpenFile(os.DevNull, os.O_WRONLY, 0)
+
+			config := test.config
+			if config == nil {
+				config = &exitConfiguration{}
+			}
+			rootCmd := &Command{
+				Name:                  "root",
+				Config:                config,
+				DefaultPointersConfig: config,
+				Run:                   func() error { return test.runErr },
+			}
+			f := New(rootCmd, test.args)
+			f.SetPrintConfig(true)
+			f.AddCommand(&Command{Name: "version", Config: &struct{}{}, DefaultPointersConfig: &struct{}{}})
+			var errOutput bytes.Buffer
+			f.SetErrorOutput(&errOutput)
+
+			code := f.Execute()
+
+			if code != test.expectedCode {
+				t.Errorf("expected exit code %d got %d", test.expectedCode, code)
+			}
+			if !strings.HasPrefix(errOutput.String(), test.expectedOutput) || len(test.expectedOutput) == 0 && errOutput.Len() > 0 {
+				t.Errorf("expected error output %q got %q", test.expectedOutput, errOutput.String())
+			}
+		})
+	}
+}
+
+func TestExecuteUsageErrorHelp(t *testing.T) {
+	backupStdout := os.Stdout
+	defer func() {
+		os.Stdout = backupStdout
+	}()
+	r, w, _ := os.Pipe()
+	os.Stdout = w
+
+	f := New(&Command{Name: "root", Config: &exitConfiguration{}, DefaultPointersConfig: &exitConfiguration{}, Run: func() error { return nil }}, []string{"--prot=80"})
+	var errOutput bytes.Buffer
+	f.SetErrorOutput(&errOutput)
+	code := f.Execute()
+
+	if errClose := w.Close(); errClose != nil {
+		t.Fatal(errClose)
+	}
+	out, errRead := ioutil.ReadAll(r)
+	if errRead != nil {
+		t.Fatal(errRead)
+	}
+
+	if code != ExitUsage {
+		t.Errorf("expected exit code %d got %d", ExitUsage, code)
+	}
+	if !strings.HasPrefix(errOutput.String(), "Error: unknown flag: --prot") || !strings.Contains(errOutput.String(), "--port") {
+		t.Errorf("expected the error and the help on the error output got %q", errOutput.String())
+	}
+	if len(out) > 0 {
+		t.Errorf("expected nothing on stdout got %q", out)
+	}
+}
+
+func TestExecuteDefaultErrorOutput(t *testing.T) {
+	backupStderr := os.Stderr
+	defer func() {
+		os.Stderr = backupStderr
+	}()
+	r, w, _ := os.Pipe()
+	os.Stderr = w
+
+	f := New(&Command{Name: "root", Config: &exitConfiguration{}, DefaultPointersConfig: &exitConfiguration{}, Run: func() error { return errors.New("boom") }}, nil)
+	code := f.Execute()
+
+	if errClose := w.Close(); errClose != nil {
+		t.Fatal(errClose)
+	}
+	out, errRead := ioutil.ReadAll(r)
+	if errRead != nil {
+		t.Fatal(errRead)
+	}
+
+	if code != ExitFailure || string(out) != "Error: boom\n" {
+		t.Errorf("expected exit code %d and error on stderr got %d %q", ExitFailure, code, out)
+	}
+}
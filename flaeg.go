@@ -1,6 +1,7 @@
 package flaeg
 
 import (
+	"context"
 	"errors"
 	"fmt"
 	"io"
@@ -12,6 +13,7 @@ import (
 	"strings"
 	"text/tabwriter"
 	"text/template"
+	"time"
 
 	"github.com/containous/flaeg/parse"
 	flag "github.com/ogier/pflag"
@@ -49,7 +51,7 @@ func getTypesRecursive(objValue reflect.Value, flagMap map[string]reflect.Struct
 				}
 
 				if _, ok := flagMap[name]; ok {
-					return fmt.Errorf("tag already exists: %s", name)
+					return &DuplicateFlagError{Flag: name}
 				}
 				flagMap[name] = objValue.Type().Field(i)
 
@@ -59,6 +61,9 @@ func getTypesRecursive(objValue reflect.Value, flagMap map[string]reflect.Struct
 			}
 		}
 	case reflect.Ptr:
+		if valuePointerTypes[objValue.Type()] {
+			return nil
+		}
 		if len(key) > 0 {
 			field := flagMap[name]
 			field.Type = reflect.TypeOf(false)
@@ -116,28 +121,57 @@ func parseArgs(args []string, flagMap map[string]reflect.StructField, parsers ma
 	// Disable output
 	flagSet.SetOutput(ioutil.Discard)
 
-	var err error
+	var missingParsers []*MissingParserError
+	var invalidValue *InvalidValueError
+	secretFileParsers := map[string]*secretFileParser{}
 	for flg, structField := range flagMap {
-		if parser, ok := parsers[structField.Type]; ok {
-			newParserValue := reflect.New(reflect.TypeOf(parser).Elem())
-			newParserValue.Elem().Set(reflect.ValueOf(parser).Elem())
-			newParser := newParserValue.Interface().(parse.Parser)
-
+		newParser, errParser := newFieldParser(structField, parsers)
+		switch errParser {
+		case nil:
+			recorder := &invalidValueRecorder{Value: newParser, flag: flg, typ: structField.Type, failed: &invalidValue}
 			if short := structField.Tag.Get("short"); len(short) == 1 {
-				flagSet.VarP(newParser, flg, short, structField.Tag.Get("description"))
+				flagSet.VarP(recorder, flg, short, structField.Tag.Get("description"))
 			} else {
-				flagSet.Var(newParser, flg, structField.Tag.Get("description"))
+				flagSet.Var(recorder, flg, structField.Tag.Get("description"))
 			}
 			newParsers[flg] = newParser
-		} else {
-			err = ErrParserNotFound
+
+			// secret value from a file
+			if fileFlag := secretFileFlag(flg); isSecret(structField) && flagMap[fileFlag].Type == nil {
+				secretFileParsers[fileFlag] = &secretFileParser{flag: flg, parser: newParser}
+				flagSet.Var(&invalidValueRecorder{Value: secretFileParsers[fileFlag], flag: fileFlag, typ: reflect.TypeOf(""), failed: &invalidValue}, fileFlag, "File containing "+flg)
+			}
+		case ErrParserNotFound:
+			missingParsers = append(missingParsers, &MissingParserError{Flag: flg, Type: structField.Type})
+		default:
+			return nil, errParser
+		}
+	}
+
+	// Return var
+	valMap := make(map[string]parse.Parser)
+
+	// secret values from files given by environment variables, overwritten by flags
+	for _, fileParser := range secretFileParsers {
+		if path := os.Getenv(secretFileEnv(fileParser.flag)); len(path) > 0 {
+			if errFile := fileParser.Set(path); errFile != nil {
+				return nil, errFile
+			}
+			valMap[fileParser.flag] = fileParser.parser
 		}
 	}
 
 	// prevents case sensitivity issue
 	args = argsToLower(args)
 	if errParse := flagSet.Parse(args); errParse != nil {
-		return nil, errParse
+		if invalidValue != nil {
+			return nil, invalidValue
+		}
+		var flags []string
+		flagSet.VisitAll(func(fl *flag.Flag) {
+			flags = append(flags, fl.Name)
+		})
+		return nil, flagSetError(errParse, flags)
 	}
 
 	// Visitor in flag.Parse
@@ -149,15 +183,32 @@ func parseArgs(args []string, flagMap map[string]reflect.StructField, parsers ma
 	// Fill flagList with parsed flags
 	flagSet.Visit(visitor)
 
-	// Return var
-	valMap := make(map[string]parse.Parser)
-
 	// Return parsers on parsed flag
 	for _, flg := range flagList {
-		valMap[flg.Name] = newParsers[flg.Name]
+		if fileParser, ok := secretFileParsers[flg.Name]; ok {
+			valMap[fileParser.flag] = fileParser.parser
+		} else {
+			valMap[flg.Name] = newParsers[flg.Name]
+		}
 	}
 
-	return valMap, err
+	// Errors on secret values are kept by their parsers
+	var secretFlags []string
+	for flg, parser := range valMap {
+		if secret, ok := parser.(*secretParser); ok && secret.err != nil {
+			secretFlags = append(secretFlags, flg)
+		}
+	}
+	if len(secretFlags) > 0 {
+		sort.Strings(secretFlags)
+		flg := secretFlags[0]
+		return nil, &InvalidValueError{Flag: flg, Value: secretMask, Type: flagMap[flg].Type, Err: valMap[flg].(*secretParser).err}
+	}
+
+	if len(missingParsers) > 0 {
+		return valMap, newMissingParsersError(missingParsers)
+	}
+	return valMap, nil
 }
 
 func getDefaultValue(defaultValue reflect.Value, defaultPointersValue reflect.Value, defaultValmap map[string]reflect.Value, key string) error {
@@ -185,7 +236,7 @@ func getDefaultValue(defaultValue reflect.Value, defaultPointersValue reflect.Va
 					name = key + "." + strings.ToLower(fieldName)
 				}
 
-				if defaultValue.Field(i).Kind() != reflect.Ptr {
+				if defaultValue.Field(i).Kind() != reflect.Ptr || valuePointerTypes[defaultValue.Field(i).Type()] {
 					defaultValmap[name] = defaultValue.Field(i)
 				}
 				if err := getDefaultValue(defaultValue.Field(i), defaultPointersValue.Field(i), defaultValmap, name); err != nil {
@@ -194,6 +245,9 @@ func getDefaultValue(defaultValue reflect.Value, defaultPointersValue reflect.Va
 			}
 		}
 	case reflect.Ptr:
+		if valuePointerTypes[defaultValue.Type()] {
+			return nil
+		}
 		if !defaultPointersValue.IsNil() {
 			if len(key) != 0 {
 				// turn ptr fields to nil
@@ -254,7 +308,7 @@ func setPointersNil(objValue reflect.Value) (reflect.Value, error) {
 	starNilPointersObjVal.Set(starObjValue)
 
 	for i := 0; i < nilPointersObjVal.Elem().NumField(); i++ {
-		if field := nilPointersObjVal.Elem().Field(i); field.Kind() == reflect.Ptr && field.CanSet() {
+		if field := nilPointersObjVal.Elem().Field(i); field.Kind() == reflect.Ptr && !valuePointerTypes[field.Type()] && field.CanSet() {
 			field.Set(reflect.Zero(field.Type()))
 		}
 	}
@@ -284,7 +338,7 @@ func fillStructRecursive(objValue reflect.Value, defaultPointerValMap map[string
 					name = key + "." + strings.ToLower(fieldName)
 				}
 
-				if objValue.Field(i).Kind() != reflect.Ptr {
+				if objValue.Field(i).Kind() != reflect.Ptr || valuePointerTypes[objValue.Field(i).Type()] {
 					if val, ok := valMap[name]; ok {
 						if err := setFields(objValue.Field(i), val); err != nil {
 							return err
@@ -299,6 +353,9 @@ func fillStructRecursive(objValue reflect.Value, defaultPointerValMap map[string
 		}
 
 	case reflect.Ptr:
+		if valuePointerTypes[objValue.Type()] {
+			return nil
+		}
 		if len(key) == 0 && !objValue.IsNil() {
 			return fillStructRecursive(objValue.Elem(), defaultPointerValMap, valMap, name)
 		}
@@ -345,7 +402,7 @@ func fillStructRecursive(objValue reflect.Value, defaultPointerValMap map[string
 // SetFields sets value to fieldValue using tag as key in valMap
 func setFields(fieldValue reflect.Value, val parse.Parser) error {
 	if fieldValue.CanSet() {
-		fieldValue.Set(reflect.ValueOf(val).Elem().Convert(fieldValue.Type()))
+		fieldValue.Set(parserValue(val).Convert(fieldValue.Type()))
 	} else {
 		return fmt.Errorf("%s is not settable", fieldValue.Type().String())
 	}
@@ -362,7 +419,8 @@ func PrintError(err error, flagMap map[string]reflect.StructField, defaultValmap
 	if err != flag.ErrHelp {
 		fmt.Printf("Error: %s\n", err)
 	}
-	if !strings.Contains(err.Error(), ":No parser for type") {
+	var missingParser *MissingParserError
+	if !errors.As(err, &missingParser) {
 		_ = PrintHelp(flagMap, defaultValmap, parsers)
 	}
 	return err
@@ -390,12 +448,20 @@ func Load(config interface{}, defaultValue interface{}, args []string) error {
 // DefaultPointersConfig contains default pointers values: those values are set on pointers fields if their flags are called
 // It must be the same type(struct) as Config
 // Run is the func which launch the program using initialized configuration structure
+// RunContext replaces Run if set, and gets the context given to Flaeg.RunContext
+// PreRun and PostRun are called by Flaeg before and after a successful Run, with the parsed Config
+// PersistentPreRun is called before PreRun, for the command and, if the command is the root one, for all sub-commands:
+// it gets the parsed Config of the called command, which is the Config of a sub-command when one is called
 type Command struct {
 	Name                  string
 	Description           string
 	Config                interface{}
 	DefaultPointersConfig interface{} // TODO: case DefaultPointersConfig is nil
 	Run                   func() error
+	RunContext            func(ctx context.Context) error
+	PersistentPreRun      func(config interface{}) error
+	PreRun                func(config interface{}) error
+	PostRun               func(config interface{}) error
 	Metadata              map[string]string
 	HideHelp              bool
 }
@@ -403,6 +469,13 @@ type Command struct {
 // LoadWithCommand initializes config : struct fields given by reference, with args : arguments.
 // Some custom parsers and some subCommand may be given.
 func LoadWithCommand(cmd *Command, cmdArgs []string, customParsers map[reflect.Type]parse.Parser, subCommand []*Command) error {
+	return loadWithCommand(cmd, cmdArgs, customParsers, subCommand, nil, nil)
+}
+
+// loadWithCommand is LoadWithCommand printing the errors on args to errOutput, and returning them as usageError.
+// With a nil errOutput, errors are printed as by PrintErrorWithCommand.
+// With a non-nil output, the flag --print-config prints the configuration to output.
+func loadWithCommand(cmd *Command, cmdArgs []string, customParsers map[reflect.Type]parse.Parser, subCommand []*Command, output io.Writer, errOutput io.Writer) error {
 	parsers, err := parse.LoadParsers(customParsers)
 	if err != nil {
 		return err
@@ -417,27 +490,47 @@ func LoadWithCommand(cmd *Command, cmdArgs []string, customParsers map[reflect.T
 		return err
 	}
 
+	printConfigFormat, printConfig := "", false
+	if _, ok := tagsMap[printConfigFlag]; !ok && output != nil {
+		cmdArgs, printConfigFormat, printConfig = extractPrintConfigFlag(cmdArgs)
+	}
+
 	valMap, errParseArgs := parseArgs(cmdArgs, tagsMap, parsers)
-	if errParseArgs != nil && errParseArgs != ErrParserNotFound {
-		return PrintErrorWithCommand(errParseArgs, tagsMap, defaultValMap, parsers, cmd, subCommand)
+	if errParseArgs != nil && !errors.Is(errParseArgs, ErrParserNotFound) {
+		if errOutput == nil {
+			return printErrorWithCommand(output != nil, errParseArgs, tagsMap, defaultValMap, parsers, cmd, subCommand)
+		}
+		return printUsageError(errOutput, output != nil, errParseArgs, tagsMap, defaultValMap, parsers, cmd, subCommand)
 	}
 
 	if err := fillStructRecursive(reflect.ValueOf(cmd.Config), defaultValMap, valMap, ""); err != nil {
 		return err
 	}
 
-	if errParseArgs == ErrParserNotFound {
+	if errParseArgs != nil {
 		return errParseArgs
 	}
 
+	if printConfig {
+		if err := DumpConfig(output, cmd, parsers, printConfigFormat); err != nil {
+			return err
+		}
+		return ErrConfigPrinted
+	}
+
 	return nil
 }
 
 // PrintHelpWithCommand generates and prints command line help for a Command
 func PrintHelpWithCommand(flagMap map[string]reflect.StructField, defaultValMap map[string]reflect.Value, parsers map[reflect.Type]parse.Parser, cmd *Command, subCmd []*Command) error {
+	return printHelpWithCommand(os.Stdout, false, flagMap, defaultValMap, parsers, cmd, subCmd)
+}
+
+// printHelpWithCommand is PrintHelpWithCommand printing to output, listing --print-config if printConfig is true
+func printHelpWithCommand(output io.Writer, printConfig bool, flagMap map[string]reflect.StructField, defaultValMap map[string]reflect.Value, parsers map[reflect.Type]parse.Parser, cmd *Command, subCmd []*Command) error {
 	// Hide command from help
 	if cmd != nil && cmd.HideHelp {
-		return fmt.Errorf("command %s not found", cmd.Name)
+		return &UnknownCommandError{Command: cmd.Name}
 	}
 
 	// Define a templates
@@ -483,20 +576,22 @@ Flags:
 	if err != nil {
 		return err
 	}
-	err = tmplHelper.Execute(os.Stdout, tempStruct)
+	err = tmplHelper.Execute(output, tempStruct)
 	if err != nil {
 		return err
 	}
 
-	return printFlagsDescriptionsDefaultValues(flagMap, defaultValMap, parsers, os.Stdout)
+	return printFlagsDescriptionsDefaultValues(flagMap, defaultValMap, parsers, printConfig, output)
 }
 
-func printFlagsDescriptionsDefaultValues(flagMap map[string]reflect.StructField, defaultValMap map[string]reflect.Value, parsers map[reflect.Type]parse.Parser, output io.Writer) error {
+func printFlagsDescriptionsDefaultValues(flagMap map[string]reflect.StructField, defaultValMap map[string]reflect.Value, parsers map[reflect.Type]parse.Parser, printConfig bool, output io.Writer) error {
 	// Sort alphabetically & Delete unparsable flags in a slice
 	var flags []string
+	flagParsers := map[string]parse.Parser{}
 	for flg, field := range flagMap {
-		if _, ok := parsers[field.Type]; ok {
+		if parser, err := newFieldParser(field, parsers); err == nil {
 			flags = append(flags, flg)
+			flagParsers[flg] = parser
 		}
 	}
 	sort.Strings(flags)
@@ -517,19 +612,30 @@ func printFlagsDescriptionsDefaultValues(flagMap map[string]reflect.StructField,
 
 		// flag on pointer ?
 		if defVal, ok := defaultValMap[flg]; ok {
-			if defVal.Kind() != reflect.Ptr {
+			if defVal.Kind() != reflect.Ptr || valuePointerTypes[defVal.Type()] {
 				// Set defaultValue on parsers
-				parsers[field.Type].SetValue(defaultValMap[flg].Interface())
+				setParserValue(flagParsers[flg], defaultValMap[flg])
 			}
 
-			if defVal := parsers[field.Type].String(); len(defVal) > 0 {
+			if defVal := helpString(flagParsers[flg]); len(defVal) > 0 {
+				if isSecret(field) {
+					defVal = secretMask
+				}
 				defaultValues = append(defaultValues, fmt.Sprintf("(default \"%s\")", defVal))
 			} else {
 				defaultValues = append(defaultValues, "")
 			}
 		}
 
-		splittedDescriptions := split(field.Tag.Get("description"), 80)
+		description := field.Tag.Get("description")
+		if enum, ok := parserEnum(flagParsers[flg]); ok {
+			description += " (one of: " + strings.Join(enum.Choices(), ", ") + ")"
+		}
+		if fileFlag := secretFileFlag(flg); isSecret(field) && flagMap[fileFlag].Type == nil {
+			description += " (or from the file given by --" + fileFlag + " or $" + secretFileEnv(flg) + ")"
+		}
+
+		splittedDescriptions := split(description, 80)
 		for i, description := range splittedDescriptions {
 			descriptions = append(descriptions, description)
 			if i != 0 {
@@ -546,6 +652,14 @@ func printFlagsDescriptionsDefaultValues(flagMap map[string]reflect.StructField,
 	descriptions = append(descriptions, "Print Help (this message) and exit")
 	defaultValues = append(defaultValues, "")
 
+	// add print-config flag
+	if _, ok := flagMap[printConfigFlag]; !ok && printConfig {
+		shortFlagsWithDash = append(shortFlagsWithDash, "")
+		flagsWithDash = append(flagsWithDash, "--"+printConfigFlag)
+		descriptions = append(descriptions, "Print the configuration and exit")
+		defaultValues = append(defaultValues, "")
+	}
+
 	return displayTab(output, shortFlagsWithDash, flagsWithDash, descriptions, defaultValues)
 }
 
@@ -584,11 +698,16 @@ func displayTab(output io.Writer, columns ...[]string) error {
 
 // PrintErrorWithCommand takes a not nil error and prints command line help
 func PrintErrorWithCommand(err error, flagMap map[string]reflect.StructField, defaultValMap map[string]reflect.Value, parsers map[reflect.Type]parse.Parser, cmd *Command, subCmd []*Command) error {
+	return printErrorWithCommand(false, err, flagMap, defaultValMap, parsers, cmd, subCmd)
+}
+
+// printErrorWithCommand is PrintErrorWithCommand listing --print-config in the help if printConfig is true
+func printErrorWithCommand(printConfig bool, err error, flagMap map[string]reflect.StructField, defaultValMap map[string]reflect.Value, parsers map[reflect.Type]parse.Parser, cmd *Command, subCmd []*Command) error {
 	if err != flag.ErrHelp {
 		fmt.Printf("Error here : %s\n", err)
 	}
 
-	if errHelp := PrintHelpWithCommand(flagMap, defaultValMap, parsers, cmd, subCmd); errHelp != nil {
+	if errHelp := printHelpWithCommand(os.Stdout, printConfig, flagMap, defaultValMap, parsers, cmd, subCmd); errHelp != nil {
 		return errHelp
 	}
 
@@ -604,6 +723,16 @@ type Flaeg struct {
 	args          []string
 	commandArgs   []string
 	customParsers map[reflect.Type]parse.Parser
+	printConfig   bool      // the flag --print-config prints the configuration
+	output        io.Writer // writer of the configuration printed by --print-config
+	errOutput     io.Writer
+	responseFiles bool // response files (@path) in args are expanded
+	argsExpanded  bool // response files in args are expanded
+	strict        bool // fails before parsing if a flag has no parser
+	prefixMatch   bool // commands and long flags can be given by unique prefixes
+	middlewares   []Middleware
+	cancelSignals []os.Signal   // signals canceling the context of the command
+	gracePeriod   time.Duration // time left to the command to return once canceled by a signal
 }
 
 // New creates and initialize a pointer on Flaeg
@@ -625,8 +754,49 @@ func (f *Flaeg) AddParser(typ reflect.Type, parser parse.Parser) {
 	f.customParsers[typ] = parser
 }
 
+// SetStrict enables or disables the strict mode: in strict mode, Parse and Run fail before parsing
+// if a flagged field of the command has no parser, instead of loading the other flags.
+func (f *Flaeg) SetStrict(strict bool) {
+	f.strict = strict
+}
+
+// SetResponseFiles enables or disables the response files: the arguments @path are replaced
+// by the arguments read from the file path, before the command and the flags are parsed.
+func (f *Flaeg) SetResponseFiles(responseFiles bool) {
+	f.responseFiles = responseFiles
+}
+
+// SetPrintConfig enables or disables the flag --print-config[=format]: Parse and Run print the loaded configuration
+// in the format given (toml by default) to the output set by SetOutput, and return ErrConfigPrinted.
+func (f *Flaeg) SetPrintConfig(printConfig bool) {
+	f.printConfig = printConfig
+}
+
+// SetPrefixMatching enables or disables the prefix matching: commands and long flags can be given
+// by a prefix of their names if a single one starts with it, like "ver" for "version" or --db.con for --db.connectionmax64.
+func (f *Flaeg) SetPrefixMatching(prefixMatch bool) {
+	f.prefixMatch = prefixMatch
+}
+
+// RunFunc runs a parsed command: its hooks and its Run or RunContext function
+type RunFunc func(ctx context.Context, cmd *Command) error
+
+// Middleware wraps the RunFunc of the commands, to add behaviors like timing, logging or panic recovery.
+// The command and its parsed configuration (cmd.Config) are given to the returned RunFunc.
+type Middleware func(next RunFunc) RunFunc
+
+// Use adds middlewares around the run of every command. The first middleware added is the outermost.
+func (f *Flaeg) Use(middlewares ...Middleware) {
+	f.middlewares = append(f.middlewares, middlewares...)
+}
+
 // Run calls the command with flags given as arguments
 func (f *Flaeg) Run() error {
+	return f.RunContext(context.Background())
+}
+
+// RunContext calls the command with flags given as arguments, giving ctx to its RunContext function
+func (f *Flaeg) RunContext(ctx context.Context) error {
 	if f.calledCommand == nil {
 		if _, _, err := f.findCommandWithCommandArgs(); err != nil {
 			return err
@@ -636,22 +806,95 @@ func (f *Flaeg) Run() error {
 	if _, err := f.Parse(f.calledCommand); err != nil {
 		return err
 	}
-	return f.calledCommand.Run()
+	return f.run(ctx, f.calledCommand)
+}
+
+// run calls the RunContext or the Run function of the parsed command cmd between its hooks,
+// with ctx canceled on signals if enabled by SetCancelOnSignals
+func (f *Flaeg) run(ctx context.Context, cmd *Command) error {
+	var runFunc RunFunc = func(ctx context.Context, cmd *Command) error {
+		for _, preRun := range f.preRunHooks(cmd) {
+			if err := preRun(cmd.Config); err != nil {
+				return err
+			}
+		}
+
+		var err error
+		if cmd.RunContext != nil {
+			err = cmd.RunContext(ctx)
+		} else {
+			err = cmd.Run()
+		}
+		if err != nil || cmd.PostRun == nil {
+			return err
+		}
+		return cmd.PostRun(cmd.Config)
+	}
+
+	// the first middleware is the outermost
+	for i := len(f.middlewares) - 1; i >= 0; i-- {
+		runFunc = f.middlewares[i](runFunc)
+	}
+
+	if f.cancelSignals == nil {
+		return runFunc(ctx, cmd)
+	}
+	return runWithSignals(ctx, func(ctx context.Context) error { return runFunc(ctx, cmd) }, f.cancelSignals, f.gracePeriod)
 }
 
 // Parse calls Flaeg Load Function end returns the parsed command structure (by reference)
 // It returns nil and a not nil error if it fails
 func (f *Flaeg) Parse(cmd *Command) (*Command, error) {
+	return f.parse(cmd, nil)
+}
+
+// parse is Parse printing the errors on args to errOutput, see loadWithCommand
+func (f *Flaeg) parse(cmd *Command, errOutput io.Writer) (*Command, error) {
 	if f.calledCommand == nil {
+		if err := f.expandArgs(); err != nil {
+			return cmd, err
+		}
 		f.commandArgs = f.args
 	}
 
-	if err := LoadWithCommand(cmd, f.commandArgs, f.customParsers, f.commands); err != nil {
+	if f.strict {
+		if err := CheckParsers(cmd.Config, f.customParsers); err != nil {
+			return cmd, err
+		}
+	}
+
+	if f.prefixMatch {
+		flags, err := getCommandFlags(cmd.Config, f.customParsers, f.printConfig)
+		if err != nil {
+			return cmd, err
+		}
+		if f.commandArgs, err = expandFlagPrefixes(f.commandArgs, flags); err != nil {
+			return cmd, err
+		}
+	}
+
+	if err := loadWithCommand(cmd, f.commandArgs, f.customParsers, f.commands, f.printConfigOutput(), errOutput); err != nil {
 		return cmd, err
 	}
 	return cmd, nil
 }
 
+// preRunHooks returns the hooks to call before running cmd, all with the parsed configuration of cmd:
+// the PersistentPreRun of the root command, the PersistentPreRun of cmd if it is a sub-command, and its PreRun
+func (f *Flaeg) preRunHooks(cmd *Command) []func(config interface{}) error {
+	var hooks []func(config interface{}) error
+	if rootCmd := f.commands[0]; rootCmd != cmd && rootCmd.PersistentPreRun != nil {
+		hooks = append(hooks, rootCmd.PersistentPreRun)
+	}
+	if cmd.PersistentPreRun != nil {
+		hooks = append(hooks, cmd.PersistentPreRun)
+	}
+	if cmd.PreRun != nil {
+		hooks = append(hooks, cmd.PreRun)
+	}
+	return hooks
+}
+
 // splitArgs takes args (type []string) and return command ("" if rootCommand) and command's args
 func splitArgs(args []string) (string, []string) {
 	if len(args) >= 1 && len(args[0]) >= 1 && string(args[0][0]) != "-" {
@@ -666,6 +909,10 @@ func splitArgs(args []string) (string, []string) {
 // findCommandWithCommandArgs returns the called command (by reference) and command's args
 // the error returned is not nil if it fails
 func (f *Flaeg) findCommandWithCommandArgs() (*Command, []string, error) {
+	if err := f.expandArgs(); err != nil {
+		return nil, []string{}, err
+	}
+
 	var commandName string
 	commandName, f.commandArgs = splitArgs(f.args)
 	if len(commandName) > 0 {
@@ -675,13 +922,46 @@ func (f *Flaeg) findCommandWithCommandArgs() (*Command, []string, error) {
 				return f.calledCommand, f.commandArgs, nil
 			}
 		}
-		return nil, []string{}, fmt.Errorf("command %s not found", commandName)
+
+		if f.prefixMatch {
+			command, err := findCommandByPrefix(commandName, f.commands[1:])
+			if err != nil {
+				return nil, []string{}, err
+			}
+			if command != nil {
+				f.calledCommand = command
+				return f.calledCommand, f.commandArgs, nil
+			}
+		}
+
+		var commandNames []string
+		for _, command := range f.commands[1:] {
+			if !command.HideHelp {
+				commandNames = append(commandNames, command.Name)
+			}
+		}
+		return nil, []string{}, &UnknownCommandError{Command: commandName, Suggestions: suggest(commandName, commandNames)}
 	}
 
 	f.calledCommand = f.commands[0]
 	return f.calledCommand, f.commandArgs, nil
 }
 
+// expandArgs replaces the response files (@path) in args by their arguments, once, if they are enabled
+func (f *Flaeg) expandArgs() error {
+	if !f.responseFiles || f.argsExpanded {
+		return nil
+	}
+
+	args, err := expandResponseFiles(f.args)
+	if err != nil {
+		return err
+	}
+	f.args = args
+	f.argsExpanded = true
+	return nil
+}
+
 // GetCommand splits args and returns the called command (by reference)
 // It returns nil and a not nil error if it fails
 func (f *Flaeg) GetCommand() (*Command, error) {
@@ -1,9 +1,12 @@
 package flaeg
 
 import (
+	"context"
 	"errors"
 	"fmt"
 	"io/ioutil"
+	"net"
+	"net/url"
 	"os"
 	"reflect"
 	"sort"
@@ -236,20 +239,47 @@ func TestLoadParsers(t *testing.T) {
 	check[reflect.TypeOf(true)] = &boolParser
 	var intParser parse.IntValue
 	check[reflect.TypeOf(1)] = &intParser
+	var int8Parser parse.Int8Value
+	check[reflect.TypeOf(int8(1))] = &int8Parser
+	var int16Parser parse.Int16Value
+	check[reflect.TypeOf(int16(1))] = &int16Parser
+	var int32Parser parse.Int32Value
+	check[reflect.TypeOf(int32(1))] = &int32Parser
 	var int64Parser parse.Int64Value
 	check[reflect.TypeOf(int64(1))] = &int64Parser
 	var uintParser parse.UintValue
 	check[reflect.TypeOf(uint(1))] = &uintParser
+	var uint8Parser parse.Uint8Value
+	check[reflect.TypeOf(uint8(1))] = &uint8Parser
+	var uint16Parser parse.Uint16Value
+	check[reflect.TypeOf(uint16(1))] = &uint16Parser
+	var uint32Parser parse.Uint32Value
+	check[reflect.TypeOf(uint32(1))] = &uint32Parser
 	var uint64Parser parse.Uint64Value
 	check[reflect.TypeOf(uint64(1))] = &uint64Parser
 	var stringParser parse.StringValue
 	check[reflect.TypeOf("")] = &stringParser
+	var float32Parser parse.Float32Value
+	check[reflect.TypeOf(float32(1.5))] = &float32Parser
 	var float64Parser parse.Float64Value
 	check[reflect.TypeOf(float64(1.5))] = &float64Parser
 	var durationParser parse.Duration
 	check[reflect.TypeOf(parse.Duration(time.Second))] = &durationParser
+	check[reflect.TypeOf(time.Second)] = &durationParser
+	var byteSizeParser parse.ByteSize
+	check[reflect.TypeOf(parse.ByteSize(0))] = &byteSizeParser
 	var timeParser parse.TimeValue
 	check[reflect.TypeOf(time.Now())] = &timeParser
+	var ipParser parse.IPValue
+	check[reflect.TypeOf(net.IP{})] = &ipParser
+	var ipNetParser parse.IPNetValue
+	check[reflect.TypeOf(net.IPNet{})] = &ipNetParser
+	var hardwareAddrParser parse.HardwareAddrValue
+	check[reflect.TypeOf(net.HardwareAddr{})] = &hardwareAddrParser
+	var urlParser parse.URLValue
+	check[reflect.TypeOf(url.URL{})] = &urlParser
+	var hostPortParser parse.HostPort
+	check[reflect.TypeOf(parse.HostPort(""))] = &hostPortParser
 
 	if len(check) != len(parsers) {
 		t.Errorf("expected %d elements in parsers got %d", len(check), len(parsers))
@@ -770,7 +800,7 @@ func TestParseArgsErrorNoParser(t *testing.T) {
 	valMap, err := parseArgs(args, flagMap, parsers)
 
 	// check
-	if err != ErrParserNotFound {
+	if !errors.Is(err, ErrParserNotFound) {
 		t.Errorf("Expected error '%v' got '%v'", ErrParserNotFound, err)
 	}
 
@@ -1746,7 +1776,7 @@ func TestLoadInitConfigAllDefaultSomeFlagErrorParser(t *testing.T) {
 
 	// TEST
 	err := Load(config, defaultPointers, args)
-	if err != ErrParserNotFound {
+	if !errors.Is(err, ErrParserNotFound) {
 		t.Errorf("Expected error %s\ngot %s", ErrParserNotFound, err)
 	}
 
@@ -2950,3 +2980,200 @@ func TestTypoPrintHelp(t *testing.T) {
 		t.Errorf("Expected help description splitted on many line")
 	}
 }
+
+func TestRunHooks(t *testing.T) {
+	type hooksConfiguration struct {
+		LogLevel string `description:"Log level"`
+	}
+
+	testCases := []struct {
+		desc     string
+		args     []string
+		failing  string
+		expected []string
+	}{
+		{
+			desc:     "root command",
+			args:     []string{"--loglevel=DEBUG"},
+			expected: []string{"root PersistentPreRun DEBUG", "root PreRun DEBUG", "root Run DEBUG", "root PostRun DEBUG"},
+		},
+		{
+			desc:     "sub-command",
+			args:     []string{"sub", "--loglevel=INFO"},
+			expected: []string{"root PersistentPreRun INFO", "sub PersistentPreRun INFO", "sub PreRun INFO", "sub Run INFO", "sub PostRun INFO"},
+		},
+		{
+			desc:     "failing PersistentPreRun",
+			args:     []string{"sub"},
+			failing:  "root PersistentPreRun",
+			expected: []string{"root PersistentPreRun "},
+		},
+		{
+			desc:     "failing PreRun",
+			args:     []string{"sub"},
+			failing:  "sub PreRun",
+			expected: []string{"root PersistentPreRun ", "sub PersistentPreRun ", "sub PreRun "},
+		},
+		{
+			desc:     "failing Run",
+			args:     []string{"sub"},
+			failing:  "sub Run",
+			expected: []string{"root PersistentPreRun ", "sub PersistentPreRun ", "sub PreRun ", "sub Run "},
+		},
+	}
+
+	for _, test := range testCases {
+		t.Run(test.desc, func(t *testing.T) {
+			var calls []string
+			hook := func(name string) func(config interface{}) error {
+				return func(config interface{}) error {
+					calls = append(calls, name+" "+config.(*hooksConfiguration).LogLevel)
+					if name == test.failing {
+						return errors.New(name + " failed")
+					}
+					return nil
+				}
+			}
+			newCommand := func(name string) *Command {
+				config := &hooksConfiguration{}
+				return &Command{
+					Name:                  name,
+					Config:                config,
+					DefaultPointersConfig: &hooksConfiguration{},
+					Run:                   func() error { return hook(name + " Run")(config) },
+					PersistentPreRun:      hook(name + " PersistentPreRun"),
+					PreRun:                hook(name + " PreRun"),
+					PostRun:               hook(name + " PostRun"),
+				}
+			}
+
+			f := New(newCommand("root"), test.args)
+			f.AddCommand(newCommand("sub"))
+			err := f.Run()
+
+			if len(test.failing) == 0 && err != nil || len(test.failing) > 0 && (err == nil || err.Error() != test.failing+" failed") {
+				t.Errorf("expected error from %q got %v", test.failing, err)
+			}
+			if !reflect.DeepEqual(calls, test.expected) {
+				t.Errorf("expected calls %q got %q", test.expected, calls)
+			}
+		})
+	}
+}
+
+func TestRunHooksConfigTypes(t *testing.T) {
+	type rootConfiguration struct {
+		LogLevel string `description:"Log level"`
+	}
+	type subConfiguration struct {
+		LogLevel string `description:"Log level"`
+		Port     int    `description:"Port"`
+	}
+
+	var calls []string
+	rootCmd := &Command{
+		Name:                  "root",
+		Config:                &rootConfiguration{LogLevel: "INFO"},
+		DefaultPointersConfig: &rootConfiguration{},
+		Run:                   func() error { return nil },
+		PersistentPreRun: func(config interface{}) error {
+			switch c := config.(type) {
+			case *rootConfiguration:
+				calls = append(calls, "root PersistentPreRun root "+c.LogLevel)
+			case *subConfiguration:
+				calls = append(calls, "root PersistentPreRun sub "+c.LogLevel)
+			}
+			return nil
+		},
+	}
+	subCmd := &Command{
+		Name:                  "sub",
+		Config:                &subConfiguration{},
+		DefaultPointersConfig: &subConfiguration{},
+		Run:                   func() error { return nil },
+		PreRun: func(config interface{}) error {
+			calls = append(calls, fmt.Sprintf("sub PreRun %d", config.(*subConfiguration).Port))
+			return nil
+		},
+	}
+
+	f := New(rootCmd, []string{"sub", "--loglevel=DEBUG", "--port=80"})
+	f.AddCommand(subCmd)
+	if err := f.Run(); err != nil {
+		t.Fatal(err)
+	}
+
+	expected := []string{"root PersistentPreRun sub DEBUG", "sub PreRun 80"}
+	if !reflect.DeepEqual(calls, expected) {
+		t.Errorf("expected calls %q got %q", expected, calls)
+	}
+}
+
+func TestRunMiddlewares(t *testing.T) {
+	type middlewaresConfiguration struct {
+		LogLevel string `description:"Log level"`
+	}
+
+	var calls []string
+	middleware := func(name string) Middleware {
+		return func(next RunFunc) RunFunc {
+			return func(ctx context.Context, cmd *Command) error {
+				calls = append(calls, name+" before "+cmd.Name+" "+cmd.Config.(*middlewaresConfiguration).LogLevel)
+				err := next(ctx, cmd)
+				calls = append(calls, fmt.Sprintf("%s after %v", name, err))
+				return err
+			}
+		}
+	}
+	recovery := func(next RunFunc) RunFunc {
+		return func(ctx context.Context, cmd *Command) (err error) {
+			defer func() {
+				if r := recover(); r != nil {
+					err = fmt.Errorf("command %s panicked: %v", cmd.Name, r)
+				}
+			}()
+			return next(ctx, cmd)
+		}
+	}
+
+	rootCmd := &Command{
+		Name:                  "root",
+		Config:                &middlewaresConfiguration{},
+		DefaultPointersConfig: &middlewaresConfiguration{},
+		Run:                   func() error { return nil },
+	}
+	subCmd := &Command{
+		Name:                  "sub",
+		Config:                &middlewaresConfiguration{},
+		DefaultPointersConfig: &middlewaresConfiguration{},
+		PreRun: func(config interface{}) error {
+			calls = append(calls, "sub PreRun")
+			return nil
+		},
+		Run: func() error {
+			calls = append(calls, "sub Run")
+			panic("boom")
+		},
+	}
+
+	f := New(rootCmd, []string{"sub", "--loglevel=DEBUG"})
+	f.AddCommand(subCmd)
+	f.Use(middleware("first"), recovery)
+	f.Use(middleware("last"))
+
+	err := f.Run()
+
+	if err == nil || err.Error() != "command sub panicked: boom" {
+		t.Errorf("expected error command sub panicked got %v", err)
+	}
+	expected := []string{
+		"first before sub DEBUG",
+		"last before sub DEBUG",
+		"sub PreRun",
+		"sub Run",
+		"first after command sub panicked: boom",
+	}
+	if !reflect.DeepEqual(calls, expected) {
+		t.Errorf("expected calls %q got %q", expected, calls)
+	}
+}
@@ -2,8 +2,12 @@ package parse
 
 import (
 	"encoding/json"
+	"errors"
 	"flag"
 	"fmt"
+	"math"
+	"net"
+	"net/url"
 	"reflect"
 	"strconv"
 	"strings"
@@ -16,6 +20,12 @@ type Parser interface {
 	SetValue(interface{})
 }
 
+// Enum is implemented by types whose values are restricted to a set of choices.
+// Flags on those types only accept the choices, case insensitively.
+type Enum interface {
+	Choices() []string
+}
+
 // BoolValue bool Value type
 type BoolValue bool
 
@@ -46,12 +56,17 @@ type BoolFlag interface {
 	IsBoolFlag() bool
 }
 
+// HelpStringer optional interface for parsers writing their values differently in the help
+type HelpStringer interface {
+	HelpString() string
+}
+
 // IntValue int Value
 type IntValue int
 
 // Set sets int value from the given string value.
 func (i *IntValue) Set(s string) error {
-	v, err := strconv.ParseInt(s, 0, 64)
+	v, err := strconv.ParseInt(s, 0, strconv.IntSize)
 	*i = IntValue(v)
 	return err
 }
@@ -66,6 +81,66 @@ func (i *IntValue) SetValue(val interface{}) {
 	*i = IntValue(val.(int))
 }
 
+// Int8Value int8 Value
+type Int8Value int8
+
+// Set sets int8 value from the given string value.
+func (i *Int8Value) Set(s string) error {
+	v, err := strconv.ParseInt(s, 0, 8)
+	*i = Int8Value(v)
+	return err
+}
+
+// Get returns the int8 value.
+func (i *Int8Value) Get() interface{} { return int8(*i) }
+
+func (i *Int8Value) String() string { return fmt.Sprintf("%v", *i) }
+
+// SetValue sets the Int8Value from the given int8-asserted value.
+func (i *Int8Value) SetValue(val interface{}) {
+	*i = Int8Value(val.(int8))
+}
+
+// Int16Value int16 Value
+type Int16Value int16
+
+// Set sets int16 value from the given string value.
+func (i *Int16Value) Set(s string) error {
+	v, err := strconv.ParseInt(s, 0, 16)
+	*i = Int16Value(v)
+	return err
+}
+
+// Get returns the int16 value.
+func (i *Int16Value) Get() interface{} { return int16(*i) }
+
+func (i *Int16Value) String() string { return fmt.Sprintf("%v", *i) }
+
+// SetValue sets the Int16Value from the given int16-asserted value.
+func (i *Int16Value) SetValue(val interface{}) {
+	*i = Int16Value(val.(int16))
+}
+
+// Int32Value int32 Value
+type Int32Value int32
+
+// Set sets int32 value from the given string value.
+func (i *Int32Value) Set(s string) error {
+	v, err := strconv.ParseInt(s, 0, 32)
+	*i = Int32Value(v)
+	return err
+}
+
+// Get returns the int32 value.
+func (i *Int32Value) Get() interface{} { return int32(*i) }
+
+func (i *Int32Value) String() string { return fmt.Sprintf("%v", *i) }
+
+// SetValue sets the Int32Value from the given int32-asserted value.
+func (i *Int32Value) SetValue(val interface{}) {
+	*i = Int32Value(val.(int32))
+}
+
 // Int64Value int64 Value
 type Int64Value int64
 
@@ -91,7 +166,7 @@ type UintValue uint
 
 // Set sets uint value from the given string value.
 func (i *UintValue) Set(s string) error {
-	v, err := strconv.ParseUint(s, 0, 64)
+	v, err := strconv.ParseUint(s, 0, strconv.IntSize)
 	*i = UintValue(v)
 	return err
 }
@@ -106,6 +181,66 @@ func (i *UintValue) SetValue(val interface{}) {
 	*i = UintValue(val.(uint))
 }
 
+// Uint8Value uint8 Value
+type Uint8Value uint8
+
+// Set sets uint8 value from the given string value.
+func (i *Uint8Value) Set(s string) error {
+	v, err := strconv.ParseUint(s, 0, 8)
+	*i = Uint8Value(v)
+	return err
+}
+
+// Get returns the uint8 value.
+func (i *Uint8Value) Get() interface{} { return uint8(*i) }
+
+func (i *Uint8Value) String() string { return fmt.Sprintf("%v", *i) }
+
+// SetValue sets the Uint8Value from the given uint8-asserted value.
+func (i *Uint8Value) SetValue(val interface{}) {
+	*i = Uint8Value(val.(uint8))
+}
+
+// Uint16Value uint16 Value
+type Uint16Value uint16
+
+// Set sets uint16 value from the given string value.
+func (i *Uint16Value) Set(s string) error {
+	v, err := strconv.ParseUint(s, 0, 16)
+	*i = Uint16Value(v)
+	return err
+}
+
+// Get returns the uint16 value.
+func (i *Uint16Value) Get() interface{} { return uint16(*i) }
+
+func (i *Uint16Value) String() string { return fmt.Sprintf("%v", *i) }
+
+// SetValue sets the Uint16Value from the given uint16-asserted value.
+func (i *Uint16Value) SetValue(val interface{}) {
+	*i = Uint16Value(val.(uint16))
+}
+
+// Uint32Value uint32 Value
+type Uint32Value uint32
+
+// Set sets uint32 value from the given string value.
+func (i *Uint32Value) Set(s string) error {
+	v, err := strconv.ParseUint(s, 0, 32)
+	*i = Uint32Value(v)
+	return err
+}
+
+// Get returns the uint32 value.
+func (i *Uint32Value) Get() interface{} { return uint32(*i) }
+
+func (i *Uint32Value) String() string { return fmt.Sprintf("%v", *i) }
+
+// SetValue sets the Uint32Value from the given uint32-asserted value.
+func (i *Uint32Value) SetValue(val interface{}) {
+	*i = Uint32Value(val.(uint32))
+}
+
 // Uint64Value uint64 Value
 type Uint64Value uint64
 
@@ -145,6 +280,26 @@ func (s *StringValue) SetValue(val interface{}) {
 	*s = StringValue(val.(string))
 }
 
+// Float32Value float32 Value
+type Float32Value float32
+
+// Set sets float32 value from the given string value.
+func (f *Float32Value) Set(s string) error {
+	v, err := strconv.ParseFloat(s, 32)
+	*f = Float32Value(v)
+	return err
+}
+
+// Get returns the float32 value.
+func (f *Float32Value) Get() interface{} { return float32(*f) }
+
+func (f *Float32Value) String() string { return fmt.Sprintf("%v", *f) }
+
+// SetValue sets the Float32Value from the given float32-asserted value.
+func (f *Float32Value) SetValue(val interface{}) {
+	*f = Float32Value(val.(float32))
+}
+
 // Float64Value float64 Value
 type Float64Value float64
 
@@ -166,7 +321,8 @@ func (f *Float64Value) SetValue(val interface{}) {
 }
 
 // Duration is a custom type suitable for parsing duration values.
-// It supports `time.ParseDuration`-compatible values and suffix-less digits; in
+// It supports `time.ParseDuration`-compatible values extended with days (d) and weeks (w) units,
+// ISO 8601 durations without years and months (P1DT2H) and suffix-less digits; in
 // the latter case, seconds are assumed.
 type Duration time.Duration
 
@@ -177,19 +333,187 @@ func (d *Duration) Set(s string) error {
 		return nil
 	}
 
-	v, err := time.ParseDuration(s)
+	v, err := parseDuration(s)
 	*d = Duration(v)
 	return err
 }
 
+const (
+	day  = 24 * time.Hour
+	week = 7 * day
+)
+
+// parseDuration parses time.ParseDuration values with days and weeks units, and ISO 8601 durations
+func parseDuration(s string) (time.Duration, error) {
+	str := s
+	sign := time.Duration(1)
+	if len(str) > 0 && (str[0] == '-' || str[0] == '+') {
+		if str[0] == '-' {
+			sign = -1
+		}
+		str = str[1:]
+	}
+
+	if len(str) > 0 && (str[0] == 'P' || str[0] == 'p') {
+		v, err := parseISO8601Duration(str)
+		if err != nil {
+			return 0, fmt.Errorf("invalid duration %q: %v", s, err)
+		}
+		return sign * v, nil
+	}
+
+	// Split days and weeks from the units supported by time.ParseDuration
+	var days float64
+	var rest string
+	for len(str) > 0 {
+		i := strings.IndexFunc(str, func(c rune) bool { return (c < '0' || c > '9') && c != '.' })
+		if i <= 0 {
+			return time.ParseDuration(s)
+		}
+		j := strings.IndexFunc(str[i:], func(c rune) bool { return (c >= '0' && c <= '9') || c == '.' })
+		if j == -1 {
+			j = len(str) - i
+		}
+		number, unit := str[:i], str[i:i+j]
+		str = str[i+j:]
+
+		switch unit {
+		case "d", "w":
+			v, err := strconv.ParseFloat(number, 64)
+			if err != nil {
+				return 0, fmt.Errorf("invalid duration %q", s)
+			}
+			if unit == "w" {
+				v *= 7
+			}
+			days += v
+		default:
+			rest += number + unit
+		}
+	}
+
+	if days == 0 {
+		return time.ParseDuration(s)
+	}
+
+	v := time.Duration(0)
+	if len(rest) > 0 {
+		var err error
+		if v, err = time.ParseDuration(rest); err != nil {
+			return 0, fmt.Errorf("invalid duration %q", s)
+		}
+	}
+	if days*float64(day) > float64(math.MaxInt64-v) {
+		return 0, fmt.Errorf("invalid duration %q: value out of range", s)
+	}
+	return sign * (time.Duration(days*float64(day)) + v), nil
+}
+
+// parseISO8601Duration parses an ISO 8601 duration like P1W, P1DT2H or PT1.5S
+// Years and months are not supported as their durations vary.
+func parseISO8601Duration(s string) (time.Duration, error) {
+	str := strings.ToUpper(s[1:])
+	if len(str) == 0 || str == "T" {
+		return 0, errors.New("empty ISO 8601 duration")
+	}
+
+	var v float64
+	inTime := false
+	for len(str) > 0 {
+		if str[0] == 'T' {
+			if inTime {
+				return 0, errors.New("duplicated T designator")
+			}
+			inTime = true
+			str = str[1:]
+			if len(str) == 0 {
+				return 0, errors.New("missing time elements after T designator")
+			}
+			continue
+		}
+
+		i := strings.IndexFunc(str, func(c rune) bool { return (c < '0' || c > '9') && c != '.' && c != ',' })
+		if i <= 0 {
+			return 0, fmt.Errorf("missing value or designator in %q", str)
+		}
+		number, err := strconv.ParseFloat(strings.Replace(str[:i], ",", ".", 1), 64)
+		if err != nil {
+			return 0, err
+		}
+
+		var unit time.Duration
+		switch designator := str[i]; {
+		case !inTime && designator == 'W':
+			unit = week
+		case !inTime && designator == 'D':
+			unit = day
+		case inTime && designator == 'H':
+			unit = time.Hour
+		case inTime && designator == 'M':
+			unit = time.Minute
+		case inTime && designator == 'S':
+			unit = time.Second
+		case !inTime && (designator == 'Y' || designator == 'M'):
+			return 0, errors.New("years and months are not supported")
+		default:
+			return 0, fmt.Errorf("unknown designator %c", designator)
+		}
+		v += number * float64(unit)
+		str = str[i+1:]
+	}
+
+	if v > math.MaxInt64 {
+		return 0, errors.New("value out of range")
+	}
+	return time.Duration(v), nil
+}
+
 // Get returns the duration value.
 func (d *Duration) Get() interface{} { return time.Duration(*d) }
 
 // String returns a string representation of the duration value.
 func (d *Duration) String() string { return (*time.Duration)(d).String() }
 
-// SetValue sets the duration from the given Duration-asserted value.
+// HelpString returns a readable representation of the duration value:
+// durations of at least one day are written with weeks or days: 2w, 1d12h.
+func (d *Duration) HelpString() string {
+	v := time.Duration(*d)
+	sign := ""
+	if v < 0 {
+		if v == math.MinInt64 {
+			return v.String()
+		}
+		sign, v = "-", -v
+	}
+	if v < day {
+		return d.String()
+	}
+
+	if v%week == 0 {
+		return fmt.Sprintf("%s%dw", sign, v/week)
+	}
+
+	str := fmt.Sprintf("%s%dd", sign, v/day)
+	if rest := v % day; rest != 0 {
+		// 12h0m0s -> 12h
+		restStr := rest.String()
+		if strings.HasSuffix(restStr, "m0s") {
+			restStr = strings.TrimSuffix(restStr, "0s")
+		}
+		if strings.HasSuffix(restStr, "h0m") {
+			restStr = strings.TrimSuffix(restStr, "0m")
+		}
+		str += restStr
+	}
+	return str
+}
+
+// SetValue sets the duration from the given Duration-asserted or time.Duration-asserted value.
 func (d *Duration) SetValue(val interface{}) {
+	if v, ok := val.(time.Duration); ok {
+		*d = Duration(v)
+		return
+	}
 	*d = val.(Duration)
 }
 
@@ -222,17 +546,136 @@ func (d *Duration) UnmarshalJSON(text []byte) error {
 	if err != nil {
 		return err
 	}
-	v, err := time.ParseDuration(value)
+	v, err := parseDuration(value)
 	*d = Duration(v)
 	return err
 }
 
+// ByteSize is a custom type suitable for parsing sizes in bytes.
+// It supports suffix-less digits (bytes), SI units (KB, MB, GB, TB, PB, EB or K, M, G, T, P, E)
+// and IEC units (KiB, MiB, GiB, TiB, PiB, EiB or Ki, Mi, Gi, Ti, Pi, Ei), case insensitively.
+// Values may be decimal: 1.5GB.
+type ByteSize uint64
+
+// byteUnits are the units of ByteSize, from the largest to the smallest
+var byteUnits = []struct {
+	name string
+	size uint64
+}{
+	{"EiB", 1 << 60}, {"EB", 1e18},
+	{"PiB", 1 << 50}, {"PB", 1e15},
+	{"TiB", 1 << 40}, {"TB", 1e12},
+	{"GiB", 1 << 30}, {"GB", 1e9},
+	{"MiB", 1 << 20}, {"MB", 1e6},
+	{"KiB", 1 << 10}, {"KB", 1e3},
+}
+
+// Set sets the size from the given string value.
+func (b *ByteSize) Set(s string) error {
+	str := strings.TrimSpace(s)
+	i := strings.IndexFunc(str, func(c rune) bool { return (c < '0' || c > '9') && c != '.' })
+	if i == -1 {
+		i = len(str)
+	}
+	number, unit := str[:i], strings.TrimSpace(str[i:])
+
+	unitSize := uint64(1)
+	if len(unit) > 0 && !strings.EqualFold(unit, "B") {
+		found := false
+		for _, u := range byteUnits {
+			// KiB, Ki, KB, K
+			if strings.EqualFold(unit, u.name) || strings.EqualFold(unit, strings.TrimSuffix(u.name, "B")) {
+				unitSize, found = u.size, true
+				break
+			}
+		}
+		if !found {
+			return fmt.Errorf("invalid byte size %q: unknown unit %q", s, unit)
+		}
+	}
+
+	if !strings.Contains(number, ".") {
+		v, err := strconv.ParseUint(number, 10, 64)
+		if err != nil {
+			return fmt.Errorf("invalid byte size %q", s)
+		}
+		if v > math.MaxUint64/unitSize {
+			return fmt.Errorf("invalid byte size %q: value out of range", s)
+		}
+		*b = ByteSize(v * unitSize)
+		return nil
+	}
+
+	v, err := strconv.ParseFloat(number, 64)
+	if err != nil {
+		return fmt.Errorf("invalid byte size %q", s)
+	}
+	if size := v * float64(unitSize); size < math.MaxUint64 {
+		*b = ByteSize(size)
+		return nil
+	}
+	return fmt.Errorf("invalid byte size %q: value out of range", s)
+}
+
+// Get returns the size in bytes.
+func (b *ByteSize) Get() interface{} { return uint64(*b) }
+
+// String returns the size with the largest unit rendering it with at most one decimal.
+func (b *ByteSize) String() string {
+	v := uint64(*b)
+	for _, u := range byteUnits {
+		if v >= u.size && (v%u.size)*10%u.size == 0 {
+			if tenth := (v % u.size) * 10 / u.size; tenth != 0 {
+				return fmt.Sprintf("%d.%d%s", v/u.size, tenth, u.name)
+			}
+			return fmt.Sprintf("%d%s", v/u.size, u.name)
+		}
+	}
+	return fmt.Sprintf("%dB", v)
+}
+
+// SetValue sets the size from the given ByteSize-asserted value.
+func (b *ByteSize) SetValue(val interface{}) {
+	*b = val.(ByteSize)
+}
+
+// MarshalText serialize the given size into a text.
+func (b *ByteSize) MarshalText() ([]byte, error) {
+	return []byte(b.String()), nil
+}
+
+// UnmarshalText deserializes the given text into a size.
+// It is meant to support TOML decoding of sizes.
+func (b *ByteSize) UnmarshalText(text []byte) error {
+	return b.Set(string(text))
+}
+
+// MarshalJSON serializes the given size in bytes.
+func (b *ByteSize) MarshalJSON() ([]byte, error) {
+	return json.Marshal(uint64(*b))
+}
+
+// UnmarshalJSON deserializes the given number of bytes or text into a size.
+func (b *ByteSize) UnmarshalJSON(text []byte) error {
+	if v, err := strconv.ParseUint(string(text), 10, 64); err == nil {
+		*b = ByteSize(v)
+		return nil
+	}
+
+	// We use json unmarshal on value because we have the quoted version
+	var value string
+	if err := json.Unmarshal(text, &value); err != nil {
+		return err
+	}
+	return b.Set(value)
+}
+
 // TimeValue time.Time Value
 type TimeValue time.Time
 
-// Set sets time.Time value from the given string value.
+// Set sets time.Time value from the given RFC3339 or relative string value (see ParseTime).
 func (t *TimeValue) Set(s string) error {
-	v, err := time.Parse(time.RFC3339, s)
+	v, err := ParseTime(s, []string{time.RFC3339}, time.UTC)
 	*t = TimeValue(v)
 	return err
 }
@@ -240,25 +683,308 @@ func (t *TimeValue) Set(s string) error {
 // Get returns the time.Time value.
 func (t *TimeValue) Get() interface{} { return time.Time(*t) }
 
-func (t *TimeValue) String() string { return (*time.Time)(t).String() }
+func (t *TimeValue) String() string { return (*time.Time)(t).Format(time.RFC3339) }
 
 // SetValue sets the TimeValue from the given time.Time-asserted value.
 func (t *TimeValue) SetValue(val interface{}) {
 	*t = TimeValue(val.(time.Time))
 }
 
+// IPValue net.IP Value
+type IPValue net.IP
+
+// Set sets net.IP value from the given IPv4 or IPv6 string value.
+func (i *IPValue) Set(s string) error {
+	v := net.ParseIP(s)
+	if v == nil {
+		return fmt.Errorf("invalid IP address %q", s)
+	}
+	*i = IPValue(v)
+	return nil
+}
+
+// Get returns the net.IP value.
+func (i *IPValue) Get() interface{} { return net.IP(*i) }
+
+func (i *IPValue) String() string {
+	if len(*i) == 0 {
+		return ""
+	}
+	return net.IP(*i).String()
+}
+
+// SetValue sets the IPValue from the given net.IP-asserted value.
+func (i *IPValue) SetValue(val interface{}) {
+	*i = IPValue(val.(net.IP))
+}
+
+// IPNetValue net.IPNet Value
+type IPNetValue net.IPNet
+
+// Set sets net.IPNet value from the given CIDR string value.
+func (i *IPNetValue) Set(s string) error {
+	_, v, err := net.ParseCIDR(s)
+	if err != nil {
+		return err
+	}
+	*i = IPNetValue(*v)
+	return nil
+}
+
+// Get returns the net.IPNet value.
+func (i *IPNetValue) Get() interface{} { return net.IPNet(*i) }
+
+func (i *IPNetValue) String() string {
+	if len(i.IP) == 0 {
+		return ""
+	}
+	return (*net.IPNet)(i).String()
+}
+
+// SetValue sets the IPNetValue from the given net.IPNet-asserted value.
+func (i *IPNetValue) SetValue(val interface{}) {
+	*i = IPNetValue(val.(net.IPNet))
+}
+
+// HardwareAddrValue net.HardwareAddr Value
+type HardwareAddrValue net.HardwareAddr
+
+// Set sets net.HardwareAddr value from the given MAC address string value.
+func (h *HardwareAddrValue) Set(s string) error {
+	v, err := net.ParseMAC(s)
+	if err != nil {
+		return err
+	}
+	*h = HardwareAddrValue(v)
+	return nil
+}
+
+// Get returns the net.HardwareAddr value.
+func (h *HardwareAddrValue) Get() interface{} { return net.HardwareAddr(*h) }
+
+func (h *HardwareAddrValue) String() string { return net.HardwareAddr(*h).String() }
+
+// SetValue sets the HardwareAddrValue from the given net.HardwareAddr-asserted value.
+func (h *HardwareAddrValue) SetValue(val interface{}) {
+	*h = HardwareAddrValue(val.(net.HardwareAddr))
+}
+
+// URLValue url.URL Value
+type URLValue url.URL
+
+// Set sets url.URL value from the given string value.
+// The URL must be absolute.
+func (u *URLValue) Set(s string) error {
+	v, err := url.Parse(s)
+	if err != nil {
+		return err
+	}
+	if len(v.Scheme) == 0 {
+		return fmt.Errorf("invalid URL %q: missing scheme", s)
+	}
+	*u = URLValue(*v)
+	return nil
+}
+
+// Get returns the url.URL value.
+func (u *URLValue) Get() interface{} { return url.URL(*u) }
+
+func (u *URLValue) String() string { return (*url.URL)(u).String() }
+
+// SetValue sets the URLValue from the given url.URL-asserted value.
+func (u *URLValue) SetValue(val interface{}) {
+	*u = URLValue(val.(url.URL))
+}
+
+// HostPort is a custom type suitable for parsing host:port values.
+// The host may be empty, the port must be a number between 0 and 65535.
+type HostPort string
+
+// Set sets the host:port from the given string value.
+func (h *HostPort) Set(s string) error {
+	_, port, err := net.SplitHostPort(s)
+	if err != nil {
+		return err
+	}
+	if _, err := strconv.ParseUint(port, 10, 16); err != nil {
+		return fmt.Errorf("invalid port %q in %q", port, s)
+	}
+	*h = HostPort(s)
+	return nil
+}
+
+// Get returns the host:port value.
+func (h *HostPort) Get() interface{} { return *h }
+
+func (h *HostPort) String() string { return string(*h) }
+
+// SetValue sets the host:port from the given HostPort-asserted value.
+func (h *HostPort) SetValue(val interface{}) {
+	*h = val.(HostPort)
+}
+
+// Host returns the host part of the host:port value.
+func (h HostPort) Host() string {
+	host, _, _ := net.SplitHostPort(string(h))
+	return host
+}
+
+// Port returns the port part of the host:port value.
+func (h HostPort) Port() int {
+	_, port, _ := net.SplitHostPort(string(h))
+	v, _ := strconv.Atoi(port)
+	return v
+}
+
+// ListParser is implemented by parsers of lists.
+// Their Set method splits the given value on ListSeparators,
+// AddElements adds already split elements to the list.
+type ListParser interface {
+	Parser
+	AddElements(elements []string) error
+}
+
+// ListSeparators are the default separators between the elements of a list value
+const ListSeparators = ",;"
+
+// SplitList splits str on any of the separators.
+// An element starting with a double or single quote is quoted up to the matching quote
+// followed by a separator or the end of str: it may contain separators,
+// and a backslash escapes the quote or a backslash.
+// Other elements are taken as is, quotes and backslashes included.
+// Empty elements are dropped unless they are quoted.
+func SplitList(str string, separators string) []string {
+	var elements []string
+
+	runes := []rune(str)
+	for i := 0; i <= len(runes); i++ {
+		if elem, next, ok := splitQuoted(runes, i, separators); ok {
+			elements = append(elements, elem)
+			i = next
+			continue
+		}
+
+		start := i
+		for i < len(runes) && !strings.ContainsRune(separators, runes[i]) {
+			i++
+		}
+		if i > start {
+			elements = append(elements, string(runes[start:i]))
+		}
+	}
+	return elements
+}
+
+// JoinList joins elements with the first of the separators, so that SplitList gives them back:
+// empty elements, elements containing a separator and elements starting with a quote are quoted.
+func JoinList(elements []string, separators string) string {
+	quoter := strings.NewReplacer(`\`, `\\`, `"`, `\"`)
+	values := make([]string, len(elements))
+	for i, elem := range elements {
+		if len(elem) == 0 || strings.ContainsAny(elem, separators) || elem[0] == '"' || elem[0] == '\'' {
+			elem = `"` + quoter.Replace(elem) + `"`
+		}
+		values[i] = elem
+	}
+	return strings.Join(values, string([]rune(separators)[:1]))
+}
+
+// splitQuoted reads the quoted element starting at runes[start], and returns it unquoted
+// with the index of the separator or the end following it.
+// It returns false if runes[start] is not a quote, or if the quote is not closed before a separator or the end.
+func splitQuoted(runes []rune, start int, separators string) (string, int, bool) {
+	if start >= len(runes) || runes[start] != '"' && runes[start] != '\'' {
+		return "", 0, false
+	}
+	quote := runes[start]
+
+	var elem strings.Builder
+	for i := start + 1; i < len(runes); i++ {
+		c := runes[i]
+		switch {
+		case c == '\\' && i+1 < len(runes) && (runes[i+1] == quote || runes[i+1] == '\\'):
+			i++
+			elem.WriteRune(runes[i])
+		case c == quote && (i+1 == len(runes) || strings.ContainsRune(separators, runes[i+1])):
+			return elem.String(), i + 1, true
+		default:
+			elem.WriteRune(c)
+		}
+	}
+	return "", 0, false
+}
+
+// timeLayouts are the layouts of the time package which can be named instead of written
+var timeLayouts = map[string]string{
+	"ANSIC":       time.ANSIC,
+	"UnixDate":    time.UnixDate,
+	"RubyDate":    time.RubyDate,
+	"RFC822":      time.RFC822,
+	"RFC822Z":     time.RFC822Z,
+	"RFC850":      time.RFC850,
+	"RFC1123":     time.RFC1123,
+	"RFC1123Z":    time.RFC1123Z,
+	"RFC3339":     time.RFC3339,
+	"RFC3339Nano": time.RFC3339Nano,
+	"Kitchen":     time.Kitchen,
+}
+
+// TimeLayout returns the layout named name (like RFC3339) in the time package,
+// or name itself if it is not a layout name.
+func TimeLayout(name string) string {
+	if layout, ok := timeLayouts[name]; ok {
+		return layout
+	}
+	return name
+}
+
+// timeNow returns the current time, used by relative time values
+var timeNow = time.Now
+
+// ParseTime parses s with the first matching layout.
+// Values without time zone are in loc.
+// s can also be relative to the current time: now, now-24h, now+1d (see Duration for the syntax).
+func ParseTime(s string, layouts []string, loc *time.Location) (time.Time, error) {
+	if str := strings.TrimSpace(s); strings.HasPrefix(strings.ToLower(str), "now") {
+		v := timeNow().In(loc)
+		if offset := strings.Replace(str[len("now"):], " ", "", -1); len(offset) > 0 {
+			if offset[0] != '-' && offset[0] != '+' {
+				return time.Time{}, fmt.Errorf("invalid relative time %q", s)
+			}
+			d, err := parseDuration(offset)
+			if err != nil {
+				return time.Time{}, fmt.Errorf("invalid relative time %q: %v", s, err)
+			}
+			v = v.Add(d)
+		}
+		return v, nil
+	}
+
+	var err error
+	for _, layout := range layouts {
+		var v time.Time
+		if v, err = time.ParseInLocation(TimeLayout(layout), s, loc); err == nil {
+			return v, nil
+		}
+	}
+	if len(layouts) > 1 {
+		return time.Time{}, fmt.Errorf("cannot parse %q with layouts %q", s, layouts)
+	}
+	return time.Time{}, err
+}
+
 // SliceStrings parse slice of strings
 type SliceStrings []string
 
 // Set adds strings elem into the the parser.
-// It splits str on , and ;
+// It splits str on , and ; (see SplitList for quoting)
 func (s *SliceStrings) Set(str string) error {
-	fargs := func(c rune) bool {
-		return c == ',' || c == ';'
-	}
-	// get function
-	slice := strings.FieldsFunc(str, fargs)
-	*s = append(*s, slice...)
+	return s.AddElements(SplitList(str, ListSeparators))
+}
+
+// AddElements adds strings elements into the parser.
+func (s *SliceStrings) AddElements(elements []string) error {
+	*s = append(*s, elements...)
 	return nil
 }
 
@@ -275,7 +1001,8 @@ func (s *SliceStrings) SetValue(val interface{}) {
 
 // LoadParsers loads default parsers and custom parsers given as parameter.
 // Return a map [reflect.Type]parsers
-// bool, int, int64, uint, uint64, float64,
+// bool, int, int8, int16, int32, int64, uint, uint8, uint16, uint32, uint64, string, float32, float64,
+// Duration, time.Duration, ByteSize, time.Time, net.IP, net.IPNet, net.HardwareAddr, url.URL, HostPort
 func LoadParsers(customParsers map[reflect.Type]Parser) (map[reflect.Type]Parser, error) {
 	parsers := map[reflect.Type]Parser{}
 
@@ -285,27 +1012,67 @@ func LoadParsers(customParsers map[reflect.Type]Parser) (map[reflect.Type]Parser
 	var intParser IntValue
 	parsers[reflect.TypeOf(1)] = &intParser
 
+	var int8Parser Int8Value
+	parsers[reflect.TypeOf(int8(1))] = &int8Parser
+
+	var int16Parser Int16Value
+	parsers[reflect.TypeOf(int16(1))] = &int16Parser
+
+	var int32Parser Int32Value
+	parsers[reflect.TypeOf(int32(1))] = &int32Parser
+
 	var int64Parser Int64Value
 	parsers[reflect.TypeOf(int64(1))] = &int64Parser
 
 	var uintParser UintValue
 	parsers[reflect.TypeOf(uint(1))] = &uintParser
 
+	var uint8Parser Uint8Value
+	parsers[reflect.TypeOf(uint8(1))] = &uint8Parser
+
+	var uint16Parser Uint16Value
+	parsers[reflect.TypeOf(uint16(1))] = &uint16Parser
+
+	var uint32Parser Uint32Value
+	parsers[reflect.TypeOf(uint32(1))] = &uint32Parser
+
 	var uint64Parser Uint64Value
 	parsers[reflect.TypeOf(uint64(1))] = &uint64Parser
 
 	var stringParser StringValue
 	parsers[reflect.TypeOf("")] = &stringParser
 
+	var float32Parser Float32Value
+	parsers[reflect.TypeOf(float32(1.5))] = &float32Parser
+
 	var float64Parser Float64Value
 	parsers[reflect.TypeOf(float64(1.5))] = &float64Parser
 
 	var durationParser Duration
 	parsers[reflect.TypeOf(Duration(time.Second))] = &durationParser
+	parsers[reflect.TypeOf(time.Second)] = &durationParser
+
+	var byteSizeParser ByteSize
+	parsers[reflect.TypeOf(ByteSize(0))] = &byteSizeParser
 
 	var timeParser TimeValue
 	parsers[reflect.TypeOf(time.Now())] = &timeParser
 
+	var ipParser IPValue
+	parsers[reflect.TypeOf(net.IP{})] = &ipParser
+
+	var ipNetParser IPNetValue
+	parsers[reflect.TypeOf(net.IPNet{})] = &ipNetParser
+
+	var hardwareAddrParser HardwareAddrValue
+	parsers[reflect.TypeOf(net.HardwareAddr{})] = &hardwareAddrParser
+
+	var urlParser URLValue
+	parsers[reflect.TypeOf(url.URL{})] = &urlParser
+
+	var hostPortParser HostPort
+	parsers[reflect.TypeOf(HostPort(""))] = &hostPortParser
+
 	for rType, parser := range customParsers {
 		parsers[rType] = parser
 	}
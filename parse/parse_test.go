@@ -2,6 +2,7 @@ package parse
 
 import (
 	"encoding/json"
+	"math"
 	"reflect"
 	"strings"
 	"testing"
@@ -34,6 +35,16 @@ func TestSliceStringsSet(t *testing.T) {
 			value:    "str1,str2;str3",
 			expected: SliceStrings{"str1", "str2", "str3"},
 		},
+		{
+			desc:     "quoted value",
+			value:    `"a,b";c`,
+			expected: SliceStrings{"a,b", "c"},
+		},
+		{
+			desc:     "apostrophes",
+			value:    `it's,ok`,
+			expected: SliceStrings{"it's", "ok"},
+		},
 	}
 
 	for _, test := range testCases {
@@ -53,6 +64,120 @@ func TestSliceStringsSet(t *testing.T) {
 	}
 }
 
+func TestSplitList(t *testing.T) {
+	testCases := []struct {
+		desc       string
+		value      string
+		separators string
+		expected   []string
+	}{
+		{
+			desc:       "empty",
+			value:      "",
+			separators: ListSeparators,
+			expected:   nil,
+		},
+		{
+			desc:       "empty elements",
+			value:      "a,,b;",
+			separators: ListSeparators,
+			expected:   []string{"a", "b"},
+		},
+		{
+			desc:       "quoted empty element",
+			value:      `a,"",b`,
+			separators: ListSeparators,
+			expected:   []string{"a", "", "b"},
+		},
+		{
+			desc:       "double quotes",
+			value:      `"^a[,;]b$",c`,
+			separators: ListSeparators,
+			expected:   []string{"^a[,;]b$", "c"},
+		},
+		{
+			desc:       "single quotes",
+			value:      `'say "hi"';c`,
+			separators: ListSeparators,
+			expected:   []string{`say "hi"`, "c"},
+		},
+		{
+			desc:       "quotes inside elements",
+			value:      `http://host/?a=1"&b=2,3"`,
+			separators: ListSeparators,
+			expected:   []string{`http://host/?a=1"&b=2`, `3"`},
+		},
+		{
+			desc:       "apostrophes",
+			value:      `it's,ok;'rock'n'roll'`,
+			separators: ListSeparators,
+			expected:   []string{"it's", "ok", "rock'n'roll"},
+		},
+		{
+			desc:       "unterminated quote",
+			value:      `a,"b,c`,
+			separators: ListSeparators,
+			expected:   []string{"a", `"b`, "c"},
+		},
+		{
+			desc:       "escapes in quotes",
+			value:      `"a\"",\,"b\\c",'d\'e'`,
+			separators: ListSeparators,
+			expected:   []string{`a"`, `\`, `b\c`, "d'e"},
+		},
+		{
+			desc:       "backslash kept",
+			value:      `\d+,C:\dir,"\w"`,
+			separators: ListSeparators,
+			expected:   []string{`\d+`, `C:\dir`, `\w`},
+		},
+		{
+			desc:       "custom separator",
+			value:      "a,b|c;d",
+			separators: "|",
+			expected:   []string{"a,b", "c;d"},
+		},
+	}
+
+	for _, test := range testCases {
+		test := test
+		t.Run(test.desc, func(t *testing.T) {
+			t.Parallel()
+
+			elements := SplitList(test.value, test.separators)
+			if !reflect.DeepEqual(elements, test.expected) {
+				t.Errorf("Got: %q\nexpected: %q", elements, test.expected)
+			}
+		})
+	}
+}
+
+func TestJoinList(t *testing.T) {
+	testCases := []struct {
+		elements   []string
+		separators string
+		expected   string
+	}{
+		{elements: nil, separators: ListSeparators, expected: ""},
+		{elements: []string{"a", "b"}, separators: ListSeparators, expected: "a,b"},
+		{elements: []string{"a,b", "c;d", "it's"}, separators: ListSeparators, expected: `"a,b","c;d",it's`},
+		{elements: []string{"", `"q`, `'q`, `C:\dir`}, separators: ListSeparators, expected: `"","\"q","'q",C:\dir`},
+		{elements: []string{`a|"b\"`, "c,d"}, separators: "|", expected: `"a|\"b\\\""|c,d`},
+	}
+
+	for _, test := range testCases {
+		value := JoinList(test.elements, test.separators)
+		if value != test.expected {
+			t.Errorf("%q: got %s, want %s", test.elements, value, test.expected)
+		}
+
+		// JoinList must be split back to the same elements
+		if elements := SplitList(value, test.separators); !reflect.DeepEqual(elements, test.elements) {
+			t.Errorf("%q: split back to %q", test.elements, elements)
+		}
+	}
+}
+
 func TestSliceStringsSetAdd(t *testing.T) {
 	slice := SliceStrings{"str1"}
 
@@ -176,6 +301,137 @@ func TestSliceStringsSetValue(t *testing.T) {
 	}
 }
 
+func TestSetSizedNumbers(t *testing.T) {
+	testCases := []struct {
+		desc     string
+		parser   Parser
+		value    string
+		expected interface{}
+	}{
+		{desc: "int8", parser: new(Int8Value), value: "-128", expected: int8(-128)},
+		{desc: "int16", parser: new(Int16Value), value: "0x7fff", expected: int16(32767)},
+		{desc: "int32", parser: new(Int32Value), value: "-2147483648", expected: int32(-2147483648)},
+		{desc: "uint8", parser: new(Uint8Value), value: "255", expected: uint8(255)},
+		{desc: "uint16", parser: new(Uint16Value), value: "65535", expected: uint16(65535)},
+		{desc: "uint32", parser: new(Uint32Value), value: "4294967295", expected: uint32(4294967295)},
+		{desc: "float32", parser: new(Float32Value), value: "1.5", expected: float32(1.5)},
+	}
+
+	for _, test := range testCases {
+		test := test
+		t.Run(test.desc, func(t *testing.T) {
+			t.Parallel()
+
+			if err := test.parser.Set(test.value); err != nil {
+				t.Fatalf("Error :%s", err)
+			}
+
+			if test.parser.Get() != test.expected {
+				t.Errorf("Got: %v\nexpected: %v", test.parser.Get(), test.expected)
+			}
+		})
+	}
+}
+
+func TestSetSizedNumbersOutOfRange(t *testing.T) {
+	testCases := []struct {
+		desc   string
+		parser Parser
+		value  string
+	}{
+		{desc: "int8", parser: new(Int8Value), value: "128"},
+		{desc: "int16", parser: new(Int16Value), value: "-32769"},
+		{desc: "int32", parser: new(Int32Value), value: "2147483648"},
+		{desc: "uint8", parser: new(Uint8Value), value: "256"},
+		{desc: "uint16", parser: new(Uint16Value), value: "70000"},
+		{desc: "uint32", parser: new(Uint32Value), value: "4294967296"},
+		{desc: "negative uint16", parser: new(Uint16Value), value: "-1"},
+		{desc: "float32", parser: new(Float32Value), value: "1e39"},
+	}
+
+	for _, test := range testCases {
+		test := test
+		t.Run(test.desc, func(t *testing.T) {
+			t.Parallel()
+
+			if err := test.parser.Set(test.value); err == nil {
+				t.Errorf("want error got nil, value %v", test.parser.Get())
+			}
+		})
+	}
+}
+
+func TestSetNetworkValues(t *testing.T) {
+	testCases := []struct {
+		desc     string
+		parser   Parser
+		value    string
+		expected string
+	}{
+		{desc: "IPv4", parser: new(IPValue), value: "192.168.1.2", expected: "192.168.1.2"},
+		{desc: "IPv6", parser: new(IPValue), value: "::1", expected: "::1"},
+		{desc: "CIDR", parser: new(IPNetValue), value: "10.0.3.4/8", expected: "10.0.0.0/8"},
+		{desc: "MAC", parser: new(HardwareAddrValue), value: "00-00-5E-00-53-01", expected: "00:00:5e:00:53:01"},
+		{desc: "URL", parser: new(URLValue), value: "https://host:8443/path?a=1&b=2", expected: "https://host:8443/path?a=1&b=2"},
+		{desc: "host port", parser: new(HostPort), value: "localhost:8080", expected: "localhost:8080"},
+		{desc: "port only", parser: new(HostPort), value: ":80", expected: ":80"},
+		{desc: "IPv6 host port", parser: new(HostPort), value: "[::1]:443", expected: "[::1]:443"},
+	}
+
+	for _, test := range testCases {
+		test := test
+		t.Run(test.desc, func(t *testing.T) {
+			t.Parallel()
+
+			if err := test.parser.Set(test.value); err != nil {
+				t.Fatalf("Error :%s", err)
+			}
+
+			if test.parser.String() != test.expected {
+				t.Errorf("Got: %s\nexpected: %s", test.parser.String(), test.expected)
+			}
+		})
+	}
+}
+
+func TestSetNetworkValuesError(t *testing.T) {
+	testCases := []struct {
+		desc   string
+		parser Parser
+		value  string
+	}{
+		{desc: "IP", parser: new(IPValue), value: "192.168.1.256"},
+		{desc: "CIDR without mask", parser: new(IPNetValue), value: "10.0.0.0"},
+		{desc: "MAC", parser: new(HardwareAddrValue), value: "00:00:5e"},
+		{desc: "URL without scheme", parser: new(URLValue), value: "host/path"},
+		{desc: "URL", parser: new(URLValue), value: "http://[::1"},
+		{desc: "host without port", parser: new(HostPort), value: "localhost"},
+		{desc: "port out of range", parser: new(HostPort), value: "localhost:70000"},
+		{desc: "named port", parser: new(HostPort), value: "localhost:http"},
+	}
+
+	for _, test := range testCases {
+		test := test
+		t.Run(test.desc, func(t *testing.T) {
+			t.Parallel()
+
+			if err := test.parser.Set(test.value); err == nil {
+				t.Errorf("want error got nil")
+			}
+		})
+	}
+}
+
+func TestHostPortParts(t *testing.T) {
+	hostPort := HostPort("[::1]:8080")
+	if hostPort.Host() != "::1" {
+		t.Errorf("Got host %s expected ::1", hostPort.Host())
+	}
+	if hostPort.Port() != 8080 {
+		t.Errorf("Got port %d expected 8080", hostPort.Port())
+	}
+}
+
 func TestSetDuration(t *testing.T) {
 	tests := []struct {
 		in  string
@@ -193,6 +449,42 @@ func TestSetDuration(t *testing.T) {
 			in:  "5m",
 			out: 5 * time.Minute,
 		},
+		{
+			in:  "7d",
+			out: 7 * 24 * time.Hour,
+		},
+		{
+			in:  "2w",
+			out: 14 * 24 * time.Hour,
+		},
+		{
+			in:  "1d12h",
+			out: 36 * time.Hour,
+		},
+		{
+			in:  "1.5d30m",
+			out: 36*time.Hour + 30*time.Minute,
+		},
+		{
+			in:  "-1w1d",
+			out: -8 * 24 * time.Hour,
+		},
+		{
+			in:  "P1DT2H",
+			out: 26 * time.Hour,
+		},
+		{
+			in:  "P2W",
+			out: 14 * 24 * time.Hour,
+		},
+		{
+			in:  "PT1M30.5S",
+			out: 90*time.Second + 500*time.Millisecond,
+		},
+		{
+			in:  "pt36h",
+			out: 36 * time.Hour,
+		},
 	}
 
 	for _, test := range tests {
@@ -212,6 +504,71 @@ func TestSetDuration(t *testing.T) {
 	}
 }
 
+func TestSetDurationError(t *testing.T) {
+	for _, value := range []string{"", "d", "1x", "1dd", "P", "PT", "P1Y", "P1M", "PT1D", "P1H", "P1DT", "1d1k"} {
+		value := value
+		t.Run(value, func(t *testing.T) {
+			t.Parallel()
+
+			var dur Duration
+			if err := dur.Set(value); err == nil {
+				t.Errorf("want error got nil, duration %v", time.Duration(dur))
+			}
+		})
+	}
+}
+
+func TestDurationHelpString(t *testing.T) {
+	testCases := []struct {
+		value    time.Duration
+		expected string
+	}{
+		{value: 10 * time.Second, expected: "10s"},
+		{value: 23*time.Hour + 59*time.Minute, expected: "23h59m0s"},
+		{value: 24 * time.Hour, expected: "1d"},
+		{value: 36 * time.Hour, expected: "1d12h"},
+		{value: 24*time.Hour + 30*time.Minute, expected: "1d30m"},
+		{value: 24*time.Hour + 10*time.Second, expected: "1d10s"},
+		{value: 25*time.Hour + 10*time.Second, expected: "1d1h0m10s"},
+		{value: 14 * 24 * time.Hour, expected: "2w"},
+		{value: 8 * 24 * time.Hour, expected: "8d"},
+		{value: -36 * time.Hour, expected: "-1d12h"},
+	}
+
+	for _, test := range testCases {
+		test := test
+		t.Run(test.expected, func(t *testing.T) {
+			t.Parallel()
+
+			dur := Duration(test.value)
+			if dur.HelpString() != test.expected {
+				t.Errorf("got %s, want %s", dur.HelpString(), test.expected)
+			}
+
+			// HelpString must be parsed back to the same duration
+			var parsed Duration
+			if err := parsed.Set(dur.HelpString()); err != nil || parsed != dur {
+				t.Errorf("got %v, %v, want %v", time.Duration(parsed), err, test.value)
+			}
+		})
+	}
+}
+
+func TestDurationMarshalText(t *testing.T) {
+	dur := Duration(7 * 24 * time.Hour)
+
+	text, err := dur.MarshalText()
+	if err != nil {
+		t.Fatal(err)
+	}
+	if string(text) != "168h0m0s" || dur.String() != "168h0m0s" {
+		t.Errorf("got %s and %s, want time.Duration syntax 168h0m0s", text, dur.String())
+	}
+	if _, err := time.ParseDuration(string(text)); err != nil {
+		t.Errorf("time.ParseDuration cannot read %s: %v", text, err)
+	}
+}
+
 func TestUnmarshalTextDuration(t *testing.T) {
 	testCases := []struct {
 		desc     string
@@ -359,6 +716,222 @@ func TestUnmarshalJsonDurationError(t *testing.T) {
 	}
 }
 
+func TestSetByteSize(t *testing.T) {
+	testCases := []struct {
+		desc     string
+		value    string
+		expected ByteSize
+		str      string
+	}{
+		{desc: "bytes", value: "512", expected: 512, str: "512B"},
+		{desc: "bytes unit", value: "512B", expected: 512, str: "512B"},
+		{desc: "zero", value: "0", expected: 0, str: "0B"},
+		{desc: "IEC", value: "64KiB", expected: 64 << 10, str: "64KiB"},
+		{desc: "IEC short", value: "2Gi", expected: 2 << 30, str: "2GiB"},
+		{desc: "SI", value: "10M", expected: 10e6, str: "10MB"},
+		{desc: "SI decimal", value: "1.5GB", expected: 1.5e9, str: "1.5GB"},
+		{desc: "IEC decimal", value: "1.5KiB", expected: 1536, str: "1.5KiB"},
+		{desc: "lowercase", value: "3mib", expected: 3 << 20, str: "3MiB"},
+		{desc: "space", value: "4 TB", expected: 4e12, str: "4TB"},
+		{desc: "not round", value: "1001", expected: 1001, str: "1001B"},
+		{desc: "max", value: "18446744073709551615", expected: math.MaxUint64, str: "18446744073709551615B"},
+	}
+
+	for _, test := range testCases {
+		test := test
+		t.Run(test.desc, func(t *testing.T) {
+			t.Parallel()
+
+			var size ByteSize
+			if err := size.Set(test.value); err != nil {
+				t.Fatalf("Error :%s", err)
+			}
+
+			if size != test.expected {
+				t.Errorf("Got: %d\nexpected: %d", size, test.expected)
+			}
+			if size.String() != test.str {
+				t.Errorf("Got: %s\nexpected: %s", size.String(), test.str)
+			}
+
+			// String must be parsed back to the same size
+			var parsed ByteSize
+			if err := parsed.Set(size.String()); err != nil || parsed != size {
+				t.Errorf("Got: %d, %v\nexpected: %d", parsed, err, size)
+			}
+		})
+	}
+}
+
+func TestSetByteSizeError(t *testing.T) {
+	testCases := []struct {
+		desc  string
+		value string
+	}{
+		{desc: "empty", value: ""},
+		{desc: "unit only", value: "MB"},
+		{desc: "negative", value: "-1"},
+		{desc: "unknown unit", value: "10XB"},
+		{desc: "bits", value: "10Mbit"},
+		{desc: "overflow", value: "20EB"},
+		{desc: "decimal overflow", value: "16.5EiB"},
+	}
+
+	for _, test := range testCases {
+		test := test
+		t.Run(test.desc, func(t *testing.T) {
+			t.Parallel()
+
+			var size ByteSize
+			if err := size.Set(test.value); err == nil {
+				t.Errorf("want error got nil, size %d", size)
+			}
+		})
+	}
+}
+
+func TestJSONByteSize(t *testing.T) {
+	size := ByteSize(64 << 20)
+	bytes, err := json.Marshal(&size)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if string(bytes) != "67108864" {
+		t.Errorf("Marshal fail: %s", bytes)
+	}
+
+	for _, value := range []string{"67108864", `"64MiB"`} {
+		var parsed ByteSize
+		if err := json.Unmarshal([]byte(value), &parsed); err != nil {
+			t.Fatal(err)
+		}
+		if parsed != size {
+			t.Errorf("Got: %d\nexpected: %d", parsed, size)
+		}
+	}
+}
+
+func TestParseTime(t *testing.T) {
+	paris, err := time.LoadLocation("Europe/Paris")
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	testCases := []struct {
+		desc     string
+		value    string
+		layouts  []string
+		loc      *time.Location
+		expected time.Time
+	}{
+		{
+			desc:     "RFC3339",
+			value:    "2016-04-20T17:39:00Z",
+			layouts:  []string{time.RFC3339},
+			loc:      time.UTC,
+			expected: time.Date(2016, 4, 20, 17, 39, 0, 0, time.UTC),
+		},
+		{
+			desc:     "date",
+			value:    "2016-04-20",
+			layouts:  []string{"2006-01-02"},
+			loc:      time.UTC,
+			expected: time.Date(2016, 4, 20, 0, 0, 0, 0, time.UTC),
+		},
+		{
+			desc:     "date in location",
+			value:    "2016-04-20",
+			layouts:  []string{"2006-01-02"},
+			loc:      paris,
+			expected: time.Date(2016, 4, 20, 0, 0, 0, 0, paris),
+		},
+		{
+			desc:     "second layout",
+			value:    "2016-04-20T17:39:00+02:00",
+			layouts:  []string{"2006-01-02", "RFC3339"},
+			loc:      time.UTC,
+			expected: time.Date(2016, 4, 20, 15, 39, 0, 0, time.UTC),
+		},
+		{
+			desc:     "named layout",
+			value:    "5:04PM",
+			layouts:  []string{"Kitchen"},
+			loc:      time.UTC,
+			expected: time.Date(0, 1, 1, 17, 4, 0, 0, time.UTC),
+		},
+	}
+
+	for _, test := range testCases {
+		test := test
+		t.Run(test.desc, func(t *testing.T) {
+			t.Parallel()
+
+			v, err := ParseTime(test.value, test.layouts, test.loc)
+			if err != nil {
+				t.Fatalf("Error :%s", err)
+			}
+
+			if !v.Equal(test.expected) {
+				t.Errorf("Got: %v\nexpected: %v", v, test.expected)
+			}
+		})
+	}
+}
+
+func TestParseTimeError(t *testing.T) {
+	testCases := []struct {
+		desc    string
+		value   string
+		layouts []string
+	}{
+		{desc: "no layout matches", value: "20/04/2016", layouts: []string{"2006-01-02", time.RFC3339}},
+		{desc: "relative without sign", value: "now24h", layouts: []string{time.RFC3339}},
+		{desc: "relative invalid duration", value: "now-24x", layouts: []string{time.RFC3339}},
+	}
+
+	for _, test := range testCases {
+		test := test
+		t.Run(test.desc, func(t *testing.T) {
+			t.Parallel()
+
+			if _, err := ParseTime(test.value, test.layouts, time.UTC); err == nil {
+				t.Errorf("want error got nil")
+			}
+		})
+	}
+}
+
+func TestParseTimeRelative(t *testing.T) {
+	now := time.Date(2018, 3, 10, 12, 0, 0, 0, time.UTC)
+	backupTimeNow := timeNow
+	defer func() {
+		timeNow = backupTimeNow
+	}()
+	timeNow = func() time.Time { return now }
+
+	testCases := []struct {
+		value    string
+		expected time.Time
+	}{
+		{value: "now", expected: now},
+		{value: "NOW", expected: now},
+		{value: "now-24h", expected: now.Add(-24 * time.Hour)},
+		{value: "now+1d12h", expected: now.Add(36 * time.Hour)},
+		{value: "now - 2w", expected: now.Add(-14 * 24 * time.Hour)},
+	}
+
+	for _, test := range testCases {
+		var value TimeValue
+		if err := value.Set(test.value); err != nil {
+			t.Fatalf("%s: %s", test.value, err)
+		}
+
+		if !time.Time(value).Equal(test.expected) {
+			t.Errorf("%s: got %v expected %v", test.value, time.Time(value), test.expected)
+		}
+	}
+}
+
 type Object struct {
 	Timeout Duration
 }
@@ -0,0 +1,477 @@
+package flaeg
+
+import (
+	"encoding"
+	"flag"
+	"fmt"
+	"io"
+	"io/ioutil"
+	"net"
+	"net/url"
+	"os"
+	"reflect"
+	"strings"
+	"time"
+
+	"github.com/containous/flaeg/parse"
+)
+
+// newFieldParser returns a new parser for the flag of structField, taken from parsers
+// and set up using the StructTag of the field.
+// It returns ErrParserNotFound if no parser matches the type of the field.
+func newFieldParser(structField reflect.StructField, parsers map[reflect.Type]parse.Parser) (parse.Parser, error) {
+	var newParser parse.Parser
+	if parser, ok := parsers[structField.Type]; ok {
+		newParser = cloneParser(parser)
+	} else if valuePointerTypes[structField.Type] && parsers[structField.Type.Elem()] != nil {
+		newParser = &pointerParser{Parser: cloneParser(parsers[structField.Type.Elem()]), typ: structField.Type}
+	} else if adapter, ok := newAdapterParser(structField.Type); ok {
+		newParser = adapter
+	} else if baseType, ok := kindTypes[structField.Type.Kind()]; ok && parsers[baseType] != nil {
+		// named type: use the parser of its underlying kind
+		newParser = &convertParser{Parser: cloneParser(parsers[baseType]), typ: baseType}
+	} else {
+		return nil, ErrParserNotFound
+	}
+
+	if sep, ok := structField.Tag.Lookup("sep"); ok {
+		listParser, ok := newParser.(parse.ListParser)
+		if !ok {
+			return nil, fmt.Errorf("field %s: sep tag on type %s which is not a list", structField.Name, structField.Type)
+		}
+		if len(sep) == 0 {
+			return nil, fmt.Errorf("field %s: empty sep tag", structField.Name)
+		}
+		newParser = &separatedListParser{ListParser: listParser, separators: sep}
+	}
+
+	if layoutTag, tz := structField.Tag.Get("layout"), structField.Tag.Get("tz"); len(layoutTag) > 0 || len(tz) > 0 {
+		if _, ok := newParser.Get().(time.Time); !ok {
+			return nil, fmt.Errorf("field %s: layout or tz tag on type %s which is not a time", structField.Name, structField.Type)
+		}
+
+		timeParser := &timeLayoutParser{Parser: newParser, layouts: []string{time.RFC3339}, location: time.UTC}
+		if len(layoutTag) > 0 {
+			timeParser.layouts = strings.Split(layoutTag, "|")
+		}
+		if len(tz) > 0 {
+			location, err := time.LoadLocation(tz)
+			if err != nil {
+				return nil, fmt.Errorf("field %s: %v", structField.Name, err)
+			}
+			timeParser.location = location
+		}
+		newParser = timeParser
+	}
+
+	if choices := fieldChoices(structField); len(choices) > 0 {
+		newParser = &choicesParser{Parser: newParser, choices: choices}
+	}
+
+	if structField.Tag.Get("fromfile") == "true" {
+		newParser = &fileParser{Parser: newParser}
+	}
+
+	if isSecret(structField) {
+		newParser = &secretParser{Parser: newParser}
+	}
+
+	return newParser, nil
+}
+
+// CheckParsers returns a MissingParsersError listing the flagged fields of config without parser,
+// or nil if every flag has a parser.
+func CheckParsers(config interface{}, customParsers map[reflect.Type]parse.Parser) error {
+	parsers, err := parse.LoadParsers(customParsers)
+	if err != nil {
+		return err
+	}
+
+	flagMap := make(map[string]reflect.StructField)
+	if err := getTypesRecursive(reflect.ValueOf(config), flagMap, ""); err != nil {
+		return err
+	}
+
+	var missingParsers []*MissingParserError
+	for flg, structField := range flagMap {
+		if _, err := newFieldParser(structField, parsers); err == ErrParserNotFound {
+			missingParsers = append(missingParsers, &MissingParserError{Flag: flg, Type: structField.Type})
+		} else if err != nil {
+			return err
+		}
+	}
+
+	if len(missingParsers) > 0 {
+		return newMissingParsersError(missingParsers)
+	}
+	return nil
+}
+
+// fieldChoices returns the allowed values of a field, given by the choices StructTag
+// or by the type of the field implementing parse.Enum
+func fieldChoices(structField reflect.StructField) []string {
+	if tag := structField.Tag.Get("choices"); len(tag) > 0 {
+		var choices []string
+		for _, choice := range strings.Split(tag, ",") {
+			if choice = strings.TrimSpace(choice); len(choice) > 0 {
+				choices = append(choices, choice)
+			}
+		}
+		return choices
+	}
+
+	if enum, ok := reflect.New(structField.Type).Interface().(parse.Enum); ok {
+		return enum.Choices()
+	}
+	return nil
+}
+
+// cloneParser returns a new parser holding a copy of the value of parser
+func cloneParser(parser parse.Parser) parse.Parser {
+	newParserValue := reflect.New(reflect.TypeOf(parser).Elem())
+	newParserValue.Elem().Set(reflect.ValueOf(parser).Elem())
+	return newParserValue.Interface().(parse.Parser)
+}
+
+// newAdapterParser returns a parser on typ if typ implements well known interfaces,
+// used when no parser is registered for typ.
+func newAdapterParser(typ reflect.Type) (parse.Parser, bool) {
+	ptrType := reflect.PtrTo(typ)
+	switch {
+	case ptrType.Implements(parserType):
+		return reflect.New(typ).Interface().(parse.Parser), true
+	case ptrType.Implements(flagValueType):
+		return &valueParser{ptr: reflect.New(typ)}, true
+	case ptrType.Implements(textUnmarshalerType) && ptrType.Implements(textMarshalerType):
+		return &textParser{ptr: reflect.New(typ)}, true
+	}
+	return nil, false
+}
+
+// parserValue returns the value held by a parser, looking through the wrappers
+// and the adapters set up by newFieldParser.
+func parserValue(parser parse.Parser) reflect.Value {
+	switch p := parser.(type) {
+	case interface{ unwrap() parse.Parser }:
+		return parserValue(p.unwrap())
+	case interface{ value() reflect.Value }:
+		return p.value()
+	}
+	return reflect.ValueOf(parser).Elem()
+}
+
+// setParserValue sets value, a value of the field type, into parser.
+// As setFields, it converts value to the type held by the parser when possible.
+func setParserValue(parser parse.Parser, value reflect.Value) {
+	if parserVal := parserValue(parser); parserVal.CanSet() && value.Type().ConvertibleTo(parserVal.Type()) {
+		parserVal.Set(value.Convert(parserVal.Type()))
+		return
+	}
+	parser.SetValue(value.Interface())
+}
+
+// parserEnum returns the choices of parser or of the parsers it wraps
+func parserEnum(parser parse.Parser) (parse.Enum, bool) {
+	for {
+		if enum, ok := parser.(parse.Enum); ok {
+			return enum, true
+		}
+		w, ok := parser.(interface{ unwrap() parse.Parser })
+		if !ok {
+			return nil, false
+		}
+		parser = w.unwrap()
+	}
+}
+
+// helpString returns the value of parser written for the help,
+// by parser or one of the parsers it wraps if it implements parse.HelpStringer
+func helpString(parser parse.Parser) string {
+	for p := parser; ; {
+		if helpStringer, ok := p.(parse.HelpStringer); ok {
+			return helpStringer.HelpString()
+		}
+		w, ok := p.(interface{ unwrap() parse.Parser })
+		if !ok {
+			return parser.String()
+		}
+		p = w.unwrap()
+	}
+}
+
+// isBoolFlag returns true if parser or one of the parsers it wraps is a boolean flag
+func isBoolFlag(parser parse.Parser) bool {
+	for {
+		if boolFlag, ok := parser.(parse.BoolFlag); ok {
+			return boolFlag.IsBoolFlag()
+		}
+		w, ok := parser.(interface{ unwrap() parse.Parser })
+		if !ok {
+			return false
+		}
+		parser = w.unwrap()
+	}
+}
+
+// separatedListParser splits list values on the separators given by the sep StructTag
+type separatedListParser struct {
+	parse.ListParser
+	separators string
+}
+
+// Set splits str on the separators and adds the elements to the list.
+func (s *separatedListParser) Set(str string) error {
+	return s.AddElements(parse.SplitList(str, s.separators))
+}
+
+func (s *separatedListParser) unwrap() parse.Parser { return s.ListParser }
+
+// listElements returns the elements held by parser if it is a list of strings,
+// and the separators its Set method splits values on
+func listElements(parser parse.Parser) ([]string, string, bool) {
+	separators := parse.ListSeparators
+	for {
+		if separated, ok := parser.(*separatedListParser); ok {
+			separators = separated.separators
+		}
+		if list, ok := parser.(parse.ListParser); ok {
+			elements, ok := list.Get().([]string)
+			return elements, separators, ok
+		}
+		w, ok := parser.(interface{ unwrap() parse.Parser })
+		if !ok {
+			return nil, "", false
+		}
+		parser = w.unwrap()
+	}
+}
+
+// choicesParser restricts the values of a parser to a set of choices
+type choicesParser struct {
+	parse.Parser
+	choices []string
+}
+
+// Set sets the choice matching s case insensitively.
+func (c *choicesParser) Set(s string) error {
+	for _, choice := range c.choices {
+		if strings.EqualFold(choice, s) {
+			return c.Parser.Set(choice)
+		}
+	}
+	return fmt.Errorf("%q is not a valid choice, valid choices are: %s", s, strings.Join(c.choices, ", "))
+}
+
+// Choices returns the allowed values.
+func (c *choicesParser) Choices() []string { return c.choices }
+
+func (c *choicesParser) unwrap() parse.Parser { return c.Parser }
+
+// timeLayoutParser parses times with the layouts and the location given by the layout and tz StructTags
+type timeLayoutParser struct {
+	parse.Parser
+	layouts  []string
+	location *time.Location
+}
+
+// Set parses s with the first matching layout.
+func (t *timeLayoutParser) Set(s string) error {
+	v, err := parse.ParseTime(s, t.layouts, t.location)
+	if err != nil {
+		return err
+	}
+	t.Parser.SetValue(v)
+	return nil
+}
+
+// String formats the time with the first layout.
+func (t *timeLayoutParser) String() string {
+	return t.Get().(time.Time).In(t.location).Format(parse.TimeLayout(t.layouts[0]))
+}
+
+func (t *timeLayoutParser) unwrap() parse.Parser { return t.Parser }
+
+// stdin is read by values given as @-
+var stdin io.Reader = os.Stdin
+
+// fileParser reads values given as @path from the file path, and from stdin for @-.
+// @@value sets @value.
+type fileParser struct {
+	parse.Parser
+}
+
+// Set sets the value from s or from the content of the file referenced by s,
+// without its trailing newlines.
+func (f *fileParser) Set(s string) error {
+	if !strings.HasPrefix(s, "@") || strings.HasPrefix(s, "@@") {
+		return f.Parser.Set(strings.TrimPrefix(s, "@"))
+	}
+
+	var content []byte
+	var err error
+	if path := s[1:]; path == "-" {
+		content, err = ioutil.ReadAll(stdin)
+	} else {
+		content, err = ioutil.ReadFile(path)
+	}
+	if err != nil {
+		return err
+	}
+	return f.Parser.Set(strings.TrimRight(string(content), "\r\n"))
+}
+
+func (f *fileParser) unwrap() parse.Parser { return f.Parser }
+
+// kindTypes links the kinds of named types to the types of the parsers to use
+var kindTypes = map[reflect.Kind]reflect.Type{
+	reflect.Bool:    reflect.TypeOf(false),
+	reflect.Int:     reflect.TypeOf(int(0)),
+	reflect.Int8:    reflect.TypeOf(int8(0)),
+	reflect.Int16:   reflect.TypeOf(int16(0)),
+	reflect.Int32:   reflect.TypeOf(int32(0)),
+	reflect.Int64:   reflect.TypeOf(int64(0)),
+	reflect.Uint:    reflect.TypeOf(uint(0)),
+	reflect.Uint8:   reflect.TypeOf(uint8(0)),
+	reflect.Uint16:  reflect.TypeOf(uint16(0)),
+	reflect.Uint32:  reflect.TypeOf(uint32(0)),
+	reflect.Uint64:  reflect.TypeOf(uint64(0)),
+	reflect.Float32: reflect.TypeOf(float32(0)),
+	reflect.Float64: reflect.TypeOf(float64(0)),
+	reflect.String:  reflect.TypeOf(""),
+}
+
+// valuePointerTypes are the pointer types set by the value of their flag, with the parser of the type pointed,
+// instead of pointers on sub-configurations enabled by a boolean flag
+var valuePointerTypes = map[reflect.Type]bool{
+	reflect.TypeOf((*url.URL)(nil)):   true,
+	reflect.TypeOf((*net.IPNet)(nil)): true,
+}
+
+// pointerParser sets a pointer value with the parser of the type pointed: the pointer is nil until a value is set
+type pointerParser struct {
+	parse.Parser
+	typ   reflect.Type // pointer type
+	isSet bool
+}
+
+// Set sets the value pointed from s.
+func (p *pointerParser) Set(s string) error {
+	if err := p.Parser.Set(s); err != nil {
+		return err
+	}
+	p.isSet = true
+	return nil
+}
+
+// Get returns the pointer value.
+func (p *pointerParser) Get() interface{} { return p.value().Convert(p.typ).Interface() }
+
+// String returns the value pointed, or an empty string if the pointer is nil.
+func (p *pointerParser) String() string {
+	if !p.isSet {
+		return ""
+	}
+	return p.Parser.String()
+}
+
+// SetValue sets val, a pointer value, into the parser.
+func (p *pointerParser) SetValue(val interface{}) {
+	v := reflect.ValueOf(val)
+	p.isSet = !v.IsNil()
+	if p.isSet {
+		setParserValue(p.Parser, v.Elem())
+	}
+}
+
+// value returns a pointer on a copy of the value pointed, or a nil pointer
+func (p *pointerParser) value() reflect.Value {
+	if !p.isSet {
+		return reflect.Zero(p.typ)
+	}
+	pointed := parserValue(p.Parser)
+	v := reflect.New(pointed.Type())
+	v.Elem().Set(pointed)
+	return v
+}
+
+// convertParser uses the parser of an underlying kind for a named type
+type convertParser struct {
+	parse.Parser
+	typ reflect.Type // type of the values handled by Parser
+}
+
+// SetValue converts val, a value of the named type, before setting it.
+func (c *convertParser) SetValue(val interface{}) {
+	c.Parser.SetValue(reflect.ValueOf(val).Convert(c.typ).Interface())
+}
+
+func (c *convertParser) unwrap() parse.Parser { return c.Parser }
+
+var (
+	parserType          = reflect.TypeOf((*parse.Parser)(nil)).Elem()
+	flagValueType       = reflect.TypeOf((*flag.Value)(nil)).Elem()
+	textUnmarshalerType = reflect.TypeOf((*encoding.TextUnmarshaler)(nil)).Elem()
+	textMarshalerType   = reflect.TypeOf((*encoding.TextMarshaler)(nil)).Elem()
+)
+
+// textParser adapts a type implementing encoding.TextUnmarshaler and encoding.TextMarshaler
+type textParser struct {
+	ptr reflect.Value // pointer on a value of the adapted type
+}
+
+// Set unmarshals the given string value.
+func (t *textParser) Set(s string) error {
+	return t.ptr.Interface().(encoding.TextUnmarshaler).UnmarshalText([]byte(s))
+}
+
+// Get returns the adapted value.
+func (t *textParser) Get() interface{} { return t.ptr.Elem().Interface() }
+
+// String marshals the adapted value.
+func (t *textParser) String() string {
+	text, err := t.ptr.Interface().(encoding.TextMarshaler).MarshalText()
+	if err != nil {
+		return ""
+	}
+	return string(text)
+}
+
+// SetValue sets the adapted value.
+func (t *textParser) SetValue(val interface{}) {
+	t.ptr.Elem().Set(reflect.ValueOf(val))
+}
+
+func (t *textParser) value() reflect.Value { return t.ptr.Elem() }
+
+// valueParser adapts a type implementing flag.Value, and optionally flag.Getter
+type valueParser struct {
+	ptr reflect.Value // pointer on a value of the adapted type
+}
+
+// Set sets the adapted value from the given string value.
+func (v *valueParser) Set(s string) error {
+	return v.ptr.Interface().(flag.Value).Set(s)
+}
+
+// Get returns the adapted value, or the result of its Get method if it implements flag.Getter.
+func (v *valueParser) Get() interface{} {
+	if getter, ok := v.ptr.Interface().(flag.Getter); ok {
+		return getter.Get()
+	}
+	return v.ptr.Elem().Interface()
+}
+
+func (v *valueParser) String() string { return v.ptr.Interface().(flag.Value).String() }
+
+// SetValue sets the adapted value.
+func (v *valueParser) SetValue(val interface{}) {
+	v.ptr.Elem().Set(reflect.ValueOf(val))
+}
+
+// IsBoolFlag returns true if the adapted value is a boolean flag
+func (v *valueParser) IsBoolFlag() bool {
+	boolFlag, ok := v.ptr.Interface().(parse.BoolFlag)
+	return ok && boolFlag.IsBoolFlag()
+}
+
+func (v *valueParser) value() reflect.Value { return v.ptr.Elem() }
@@ -0,0 +1,681 @@
+package flaeg
+
+import (
+	"bytes"
+	"errors"
+	"fmt"
+	"io/ioutil"
+	"net"
+	"net/url"
+	"os"
+	"path/filepath"
+	"reflect"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/containous/flaeg/parse"
+)
+
+type listsConfiguration struct {
+	Default   parse.SliceStrings `description:"Default separators"`
+	Pipe      parse.SliceStrings `sep:"|" description:"Pipe separated"`
+	NotAList  string             `description:"Not a list"`
+	Untouched parse.SliceStrings `sep:"|" description:"Not called"`
+}
+
+func TestLoadSeparatorTag(t *testing.T) {
+	config := &listsConfiguration{}
+	args := []string{
+		`--default="a,b";c`,
+		"--pipe=a,b|c;d",
+		"--pipe=e",
+	}
+
+	customParsers := map[reflect.Type]parse.Parser{
+		reflect.TypeOf(parse.SliceStrings{}): &parse.SliceStrings{},
+	}
+
+	if err := LoadWithParsers(config, &listsConfiguration{}, args, customParsers); err != nil {
+		t.Fatal(err)
+	}
+
+	check := &listsConfiguration{
+		Default: parse.SliceStrings{"a,b", "c"},
+		Pipe:    parse.SliceStrings{"a,b", "c;d", "e"},
+	}
+	if !reflect.DeepEqual(config, check) {
+		t.Errorf("expected %+v\ngot %+v", check, config)
+	}
+}
+
+func TestNewFieldParserSeparatorTagError(t *testing.T) {
+	parsers, err := parse.LoadParsers(map[reflect.Type]parse.Parser{
+		reflect.TypeOf(parse.SliceStrings{}): &parse.SliceStrings{},
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	testCases := []struct {
+		desc  string
+		field reflect.StructField
+	}{
+		{
+			desc: "not a list",
+			field: reflect.StructField{
+				Name: "Str",
+				Type: reflect.TypeOf(""),
+				Tag:  `sep:"|" description:"String"`,
+			},
+		},
+		{
+			desc: "empty separator",
+			field: reflect.StructField{
+				Name: "List",
+				Type: reflect.TypeOf(parse.SliceStrings{}),
+				Tag:  `sep:"" description:"List"`,
+			},
+		},
+	}
+
+	for _, test := range testCases {
+		test := test
+		t.Run(test.desc, func(t *testing.T) {
+			t.Parallel()
+
+			if _, err := newFieldParser(test.field, parsers); err == nil {
+				t.Errorf("want error got nil")
+			}
+		})
+	}
+}
+
+// level implements encoding.TextUnmarshaler and encoding.TextMarshaler
+type level int
+
+var levelNames = []string{"debug", "info", "warn", "error"}
+
+func (l *level) UnmarshalText(text []byte) error {
+	for i, name := range levelNames {
+		if strings.EqualFold(name, string(text)) {
+			*l = level(i)
+			return nil
+		}
+	}
+	return fmt.Errorf("unknown level %q", text)
+}
+
+func (l level) MarshalText() ([]byte, error) {
+	return []byte(levelNames[l]), nil
+}
+
+type textConfiguration struct {
+	Level  level  `description:"Log level"`
+	Levels *level `description:"Enable levels"`
+	Other  level  `description:"Other level"`
+}
+
+func TestLoadTextUnmarshaler(t *testing.T) {
+	config := &textConfiguration{Other: 2}
+	args := []string{"--level=ERROR"}
+
+	if err := Load(config, &textConfiguration{}, args); err != nil {
+		t.Fatal(err)
+	}
+
+	check := &textConfiguration{Level: 3, Other: 2}
+	if !reflect.DeepEqual(config, check) {
+		t.Errorf("expected %+v\ngot %+v", check, config)
+	}
+}
+
+func TestLoadTextUnmarshalerInvalidValue(t *testing.T) {
+	// catch stdout
+	backupStdout := os.Stdout
+	defer func() {
+		os.Stdout = backupStdout
+	}()
+	_, w, _ := os.Pipe()
+	os.Stdout = w
+
+	err := Load(&textConfiguration{}, &textConfiguration{}, []string{"--level=verbose"})
+	if err == nil || !strings.Contains(err.Error(), "unknown level") {
+		t.Errorf("Expected error unknown level got %v", err)
+	}
+}
+
+func TestPrintFlagsTextUnmarshalerDefault(t *testing.T) {
+	config := &textConfiguration{Other: 2}
+	help := printFlagsDefaults(t, config, &textConfiguration{})
+
+	if !strings.Contains(help, `(default "warn")`) {
+		t.Errorf("Expected default value warn got:\n%s", help)
+	}
+}
+
+// printFlagsDefaults returns the flags of config printed in the help, with defaultPointers as default pointers values
+func printFlagsDefaults(t *testing.T, config interface{}, defaultPointers interface{}) string {
+	flagMap := make(map[string]reflect.StructField)
+	if err := getTypesRecursive(reflect.ValueOf(config), flagMap, ""); err != nil {
+		t.Fatal(err)
+	}
+	defaultValMap := make(map[string]reflect.Value)
+	if err := getDefaultValue(reflect.ValueOf(config), reflect.ValueOf(defaultPointers), defaultValMap, ""); err != nil {
+		t.Fatal(err)
+	}
+	parsers, err := parse.LoadParsers(nil)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	var buf bytes.Buffer
+	if err := printFlagsDescriptionsDefaultValues(flagMap, defaultValMap, parsers, false, &buf); err != nil {
+		t.Fatal(err)
+	}
+	return buf.String()
+}
+
+// byteSize implements flag.Value
+type byteSize struct {
+	bytes uint64
+}
+
+func (b *byteSize) Set(s string) error {
+	if strings.HasSuffix(s, "K") {
+		s = strings.TrimSuffix(s, "K") + "000"
+	}
+	_, err := fmt.Sscan(s, &b.bytes)
+	return err
+}
+
+func (b *byteSize) String() string { return fmt.Sprintf("%dB", b.bytes) }
+
+// toggle implements flag.Value and flag.Getter, and is a boolean flag
+type toggle string
+
+func (t *toggle) Set(s string) error {
+	if s == "true" {
+		*t = "on"
+	} else {
+		*t = "off"
+	}
+	return nil
+}
+
+func (t *toggle) String() string { return string(*t) }
+
+func (t *toggle) Get() interface{} { return *t == "on" }
+
+func (t *toggle) IsBoolFlag() bool { return true }
+
+type valueConfiguration struct {
+	Size   byteSize `description:"Size"`
+	Toggle toggle   `description:"Toggle"`
+	Level  level    `description:"Log level"`
+}
+
+func TestLoadFlagValue(t *testing.T) {
+	config := &valueConfiguration{Toggle: "off"}
+	args := []string{"--size=64K", "--toggle", "--level=info"}
+
+	if err := Load(config, &valueConfiguration{}, args); err != nil {
+		t.Fatal(err)
+	}
+
+	check := &valueConfiguration{Size: byteSize{bytes: 64000}, Toggle: "on", Level: 1}
+	if !reflect.DeepEqual(config, check) {
+		t.Errorf("expected %+v\ngot %+v", check, config)
+	}
+}
+
+func TestNewAdapterParserFlagGetter(t *testing.T) {
+	parser, ok := newAdapterParser(reflect.TypeOf(toggle("")))
+	if !ok {
+		t.Fatal("expected an adapter parser on toggle")
+	}
+
+	if err := parser.Set("true"); err != nil {
+		t.Fatal(err)
+	}
+	if parser.Get() != true {
+		t.Errorf("expected Get true got %v", parser.Get())
+	}
+	if parserValue(parser).Interface() != toggle("on") {
+		t.Errorf("expected value on got %v", parserValue(parser))
+	}
+
+	parser.SetValue(toggle("off"))
+	if parser.String() != "off" {
+		t.Errorf("expected String off got %s", parser.String())
+	}
+}
+
+type sizedConfiguration struct {
+	Port  uint16  `description:"Port"`
+	Ratio float32 `description:"Ratio"`
+	Delta int8    `description:"Delta"`
+}
+
+func TestLoadSizedNumbers(t *testing.T) {
+	config := &sizedConfiguration{}
+	args := []string{"--port=8080", "--ratio=0.5", "--delta=-3"}
+
+	if err := Load(config, &sizedConfiguration{}, args); err != nil {
+		t.Fatal(err)
+	}
+
+	check := &sizedConfiguration{Port: 8080, Ratio: 0.5, Delta: -3}
+	if !reflect.DeepEqual(config, check) {
+		t.Errorf("expected %+v\ngot %+v", check, config)
+	}
+}
+
+func TestLoadSizedNumbersOverflow(t *testing.T) {
+	// catch stdout
+	backupStdout := os.Stdout
+	defer func() {
+		os.Stdout = backupStdout
+	}()
+	_, w, _ := os.Pipe()
+	os.Stdout = w
+
+	config := &sizedConfiguration{Port: 80}
+	err := Load(config, &sizedConfiguration{}, []string{"--port=70000"})
+	if err == nil || !strings.Contains(err.Error(), "out of range") {
+		t.Errorf("Expected error out of range got %v", err)
+	}
+	if config.Port != 80 {
+		t.Errorf("Expected port unchanged got %d", config.Port)
+	}
+}
+
+type logLevel string
+
+type port int
+
+type namedConfiguration struct {
+	LogLevel logLevel `description:"Log level"`
+	Port     port     `description:"Port"`
+	Ratios   []ratio  `description:"Ratios"`
+}
+
+type ratio float64
+
+func TestLoadNamedTypes(t *testing.T) {
+	config := &namedConfiguration{LogLevel: "INFO", Port: 80}
+	args := []string{"--loglevel=DEBUG", "--port=8080"}
+
+	err := Load(config, &namedConfiguration{}, args)
+	if !errors.Is(err, ErrParserNotFound) {
+		t.Errorf("Expected error %s got %v", ErrParserNotFound, err)
+	}
+
+	check := &namedConfiguration{LogLevel: "DEBUG", Port: 8080}
+	if !reflect.DeepEqual(config, check) {
+		t.Errorf("expected %+v\ngot %+v", check, config)
+	}
+}
+
+type enabled bool
+
+type namedBoolConfiguration struct {
+	On     enabled `description:"Enabled"`
+	Cached bool    `fromfile:"true" description:"Cached"`
+	Port   port    `description:"Port"`
+}
+
+func TestLoadNamedBool(t *testing.T) {
+	config := &namedBoolConfiguration{}
+	if err := Load(config, &namedBoolConfiguration{}, []string{"--on", "--cached", "--port=80"}); err != nil {
+		t.Fatal(err)
+	}
+
+	check := &namedBoolConfiguration{On: true, Cached: true, Port: 80}
+	if !reflect.DeepEqual(config, check) {
+		t.Errorf("expected %+v\ngot %+v", check, config)
+	}
+
+	flags, err := getCommandFlags(&namedBoolConfiguration{}, nil, false)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if !flags.names["on"] || !flags.names["cached"] || flags.names["port"] {
+		t.Errorf("expected on and cached as boolean flags got %v", flags.names)
+	}
+}
+
+func TestPrintFlagsNamedTypesDefault(t *testing.T) {
+	config := &namedConfiguration{LogLevel: "INFO", Port: 80}
+	help := printFlagsDefaults(t, config, &namedConfiguration{})
+
+	for _, check := range []string{`(default "INFO")`, `(default "80")`} {
+		if !strings.Contains(help, check) {
+			t.Errorf("Expected %s got:\n%s", check, help)
+		}
+	}
+	if strings.Contains(help, "--ratios") {
+		t.Errorf("Expected no --ratios flag got:\n%s", help)
+	}
+}
+
+// strategy implements parse.Enum
+type strategy string
+
+func (strategy) Choices() []string { return []string{"RoundRobin", "Random"} }
+
+type enumConfiguration struct {
+	LogLevel string   `choices:"debug, info,warn,error" description:"Log level"`
+	Strategy strategy `description:"Balancing strategy"`
+}
+
+func TestLoadChoices(t *testing.T) {
+	config := &enumConfiguration{LogLevel: "info", Strategy: "Random"}
+	args := []string{"--loglevel=WARN", "--strategy=roundrobin"}
+
+	if err := Load(config, &enumConfiguration{}, args); err != nil {
+		t.Fatal(err)
+	}
+
+	check := &enumConfiguration{LogLevel: "warn", Strategy: "RoundRobin"}
+	if !reflect.DeepEqual(config, check) {
+		t.Errorf("expected %+v\ngot %+v", check, config)
+	}
+}
+
+func TestLoadChoicesInvalidValue(t *testing.T) {
+	// catch stdout
+	backupStdout := os.Stdout
+	defer func() {
+		os.Stdout = backupStdout
+	}()
+	_, w, _ := os.Pipe()
+	os.Stdout = w
+
+	err := Load(&enumConfiguration{}, &enumConfiguration{}, []string{"--strategy=first"})
+	if err == nil || !strings.Contains(err.Error(), "valid choices are: RoundRobin, Random") {
+		t.Errorf("Expected error listing choices got %v", err)
+	}
+}
+
+func TestPrintFlagsChoices(t *testing.T) {
+	config := &enumConfiguration{LogLevel: "info", Strategy: "Random"}
+	help := printFlagsDefaults(t, config, &enumConfiguration{})
+
+	for _, check := range []string{
+		"Log level (one of: debug, info, warn, error)",
+		"Balancing strategy (one of: RoundRobin, Random)",
+		`(default "Random")`,
+	} {
+		if !strings.Contains(help, check) {
+			t.Errorf("Expected %s got:\n%s", check, help)
+		}
+	}
+}
+
+type networkConfiguration struct {
+	IP      net.IP         `description:"Server IP address"`
+	Network net.IPNet      `description:"Allowed network"`
+	Backend url.URL        `description:"Backend URL"`
+	Listen  parse.HostPort `description:"Listening address"`
+}
+
+func TestLoadNetworkValues(t *testing.T) {
+	config := &networkConfiguration{Listen: ":80"}
+	args := []string{
+		"--ip=10.0.0.1",
+		"--network=10.0.0.0/24",
+		"--backend=http://backend:8080/api",
+	}
+
+	if err := Load(config, &networkConfiguration{}, args); err != nil {
+		t.Fatal(err)
+	}
+
+	_, network, _ := net.ParseCIDR("10.0.0.0/24")
+	backend, _ := url.Parse("http://backend:8080/api")
+	check := &networkConfiguration{
+		IP:      net.ParseIP("10.0.0.1"),
+		Network: *network,
+		Backend: *backend,
+		Listen:  ":80",
+	}
+	if !reflect.DeepEqual(config, check) {
+		t.Errorf("expected %+v\ngot %+v", check, config)
+	}
+}
+
+type networkPointersConfiguration struct {
+	Proxy   *url.URL   `description:"Proxy URL"`
+	Trusted *net.IPNet `description:"Trusted network"`
+	Mirror  *url.URL   `description:"Mirror URL"`
+	Backup  *url.URL   `description:"Backup URL"`
+}
+
+func TestLoadNetworkPointers(t *testing.T) {
+	mirror, _ := url.Parse("https://mirror")
+	config := &networkPointersConfiguration{Mirror: mirror}
+	args := []string{"--proxy=http://proxy:3128", "--trusted=10.0.0.0/8"}
+
+	if err := Load(config, &networkPointersConfiguration{}, args); err != nil {
+		t.Fatal(err)
+	}
+
+	proxy, _ := url.Parse("http://proxy:3128")
+	_, trusted, _ := net.ParseCIDR("10.0.0.0/8")
+	check := &networkPointersConfiguration{Proxy: proxy, Trusted: trusted, Mirror: mirror}
+	if !reflect.DeepEqual(config, check) {
+		t.Errorf("expected %+v\ngot %+v", check, config)
+	}
+}
+
+func TestPrintFlagsNetworkPointersDefault(t *testing.T) {
+	mirror, _ := url.Parse("https://mirror")
+	config := &networkPointersConfiguration{Mirror: mirror}
+	help := printFlagsDefaults(t, config, &networkPointersConfiguration{})
+
+	if check := `(default "https://mirror")`; !strings.Contains(help, check) {
+		t.Errorf("Expected %s got:\n%s", check, help)
+	}
+	if strings.Count(help, "(default") != 1 {
+		t.Errorf("Expected a single default got:\n%s", help)
+	}
+}
+
+type durationConfiguration struct {
+	Retention parse.Duration `description:"Retention"`
+	Timeout   time.Duration  `description:"Timeout"`
+}
+
+func TestLoadDurations(t *testing.T) {
+	config := &durationConfiguration{Timeout: time.Second}
+	args := []string{"--retention=1w", "--timeout=1d12h"}
+
+	if err := Load(config, &durationConfiguration{}, args); err != nil {
+		t.Fatal(err)
+	}
+
+	check := &durationConfiguration{Retention: parse.Duration(7 * 24 * time.Hour), Timeout: 36 * time.Hour}
+	if !reflect.DeepEqual(config, check) {
+		t.Errorf("expected %+v\ngot %+v", check, config)
+	}
+}
+
+func TestPrintFlagsDurationsDefault(t *testing.T) {
+	config := &durationConfiguration{Retention: parse.Duration(7 * 24 * time.Hour), Timeout: 36 * time.Hour}
+	help := printFlagsDefaults(t, config, &durationConfiguration{})
+
+	for _, check := range []string{`(default "1w")`, `(default "1d12h")`} {
+		if !strings.Contains(help, check) {
+			t.Errorf("Expected %s got:\n%s", check, help)
+		}
+	}
+}
+
+type timeConfiguration struct {
+	Start    time.Time `layout:"2006-01-02|RFC3339" description:"Start date"`
+	Meeting  time.Time `layout:"2006-01-02 15:04" tz:"Europe/Paris" description:"Meeting"`
+	Deadline time.Time `description:"Deadline"`
+}
+
+func TestLoadTimeLayouts(t *testing.T) {
+	config := &timeConfiguration{}
+	args := []string{
+		"--start=2018-03-10",
+		"--meeting=2018-03-10 14:30",
+		"--deadline=2018-03-10T18:00:00Z",
+	}
+
+	if err := Load(config, &timeConfiguration{}, args); err != nil {
+		t.Fatal(err)
+	}
+
+	paris, err := time.LoadLocation("Europe/Paris")
+	if err != nil {
+		t.Fatal(err)
+	}
+	check := &timeConfiguration{
+		Start:    time.Date(2018, 3, 10, 0, 0, 0, 0, time.UTC),
+		Meeting:  time.Date(2018, 3, 10, 14, 30, 0, 0, paris),
+		Deadline: time.Date(2018, 3, 10, 18, 0, 0, 0, time.UTC),
+	}
+	if !config.Start.Equal(check.Start) || !config.Meeting.Equal(check.Meeting) || !config.Deadline.Equal(check.Deadline) {
+		t.Errorf("expected %+v\ngot %+v", check, config)
+	}
+}
+
+func TestNewFieldParserTimeTagsError(t *testing.T) {
+	parsers, err := parse.LoadParsers(nil)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	testCases := []struct {
+		desc  string
+		field reflect.StructField
+	}{
+		{
+			desc: "not a time",
+			field: reflect.StructField{
+				Name: "Str",
+				Type: reflect.TypeOf(""),
+				Tag:  `layout:"2006-01-02" description:"String"`,
+			},
+		},
+		{
+			desc: "unknown time zone",
+			field: reflect.StructField{
+				Name: "Date",
+				Type: reflect.TypeOf(time.Time{}),
+				Tag:  `tz:"Europe/Nowhere" description:"Date"`,
+			},
+		},
+	}
+
+	for _, test := range testCases {
+		test := test
+		t.Run(test.desc, func(t *testing.T) {
+			t.Parallel()
+
+			if _, err := newFieldParser(test.field, parsers); err == nil {
+				t.Errorf("want error got nil")
+			}
+		})
+	}
+}
+
+func TestPrintFlagsTimeLayoutsDefault(t *testing.T) {
+	config := &timeConfiguration{
+		Start:    time.Date(2018, 3, 10, 0, 0, 0, 0, time.UTC),
+		Meeting:  time.Date(2018, 3, 10, 13, 30, 0, 0, time.UTC),
+		Deadline: time.Date(2018, 3, 10, 18, 0, 0, 0, time.UTC),
+	}
+	help := printFlagsDefaults(t, config, &timeConfiguration{})
+
+	for _, check := range []string{`(default "2018-03-10")`, `(default "2018-03-10 14:30")`, `(default "2018-03-10T18:00:00Z")`} {
+		if !strings.Contains(help, check) {
+			t.Errorf("Expected %s got:\n%s", check, help)
+		}
+	}
+}
+
+type fileConfiguration struct {
+	Certificate string             `fromfile:"true" description:"Certificate"`
+	Query       string             `fromfile:"true" description:"SQL query"`
+	Hosts       parse.SliceStrings `fromfile:"true" sep:"\n" description:"Hosts"`
+	Token       string             `description:"Token"`
+}
+
+func TestLoadFromFile(t *testing.T) {
+	dir, err := ioutil.TempDir("", "flaeg")
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer os.RemoveAll(dir)
+
+	certificate := filepath.Join(dir, "cert.pem")
+	if err := ioutil.WriteFile(certificate, []byte("-----BEGIN CERTIFICATE-----\nMIIB\n-----END CERTIFICATE-----\n\n"), 0600); err != nil {
+		t.Fatal(err)
+	}
+	hosts := filepath.Join(dir, "hosts")
+	if err := ioutil.WriteFile(hosts, []byte("host1\nhost2,b\r\n"), 0600); err != nil {
+		t.Fatal(err)
+	}
+
+	backupStdin := stdin
+	defer func() {
+		stdin = backupStdin
+	}()
+	stdin = strings.NewReader("SELECT a, b\nFROM t;\n")
+
+	config := &fileConfiguration{}
+	args := []string{
+		"--certificate=@" + certificate,
+		"--query=@-",
+		"--hosts=@" + hosts,
+		"--token=@notafile",
+	}
+	customParsers := map[reflect.Type]parse.Parser{
+		reflect.TypeOf(parse.SliceStrings{}): &parse.SliceStrings{},
+	}
+
+	if err := LoadWithParsers(config, &fileConfiguration{}, args, customParsers); err != nil {
+		t.Fatal(err)
+	}
+
+	check := &fileConfiguration{
+		Certificate: "-----BEGIN CERTIFICATE-----\nMIIB\n-----END CERTIFICATE-----",
+		Query:       "SELECT a, b\nFROM t;",
+		Hosts:       parse.SliceStrings{"host1", "host2,b"},
+		Token:       "@notafile",
+	}
+	if !reflect.DeepEqual(config, check) {
+		t.Errorf("expected %+v\ngot %+v", check, config)
+	}
+}
+
+func TestLoadFromFileEscaped(t *testing.T) {
+	config := &fileConfiguration{}
+	if err := Load(config, &fileConfiguration{}, []string{"--certificate=@@literal"}); err != nil {
+		t.Fatal(err)
+	}
+
+	if config.Certificate != "@literal" {
+		t.Errorf("expected @literal got %s", config.Certificate)
+	}
+}
+
+func TestLoadFromFileMissing(t *testing.T) {
+	// catch stdout
+	backupStdout := os.Stdout
+	defer func() {
+		os.Stdout = backupStdout
+	}()
+	_, w, _ := os.Pipe()
+	os.Stdout = w
+
+	err := Load(&fileConfiguration{}, &fileConfiguration{}, []string{"--certificate=@/does/not/exist"})
+	if err == nil || !strings.Contains(err.Error(), "/does/not/exist") {
+		t.Errorf("Expected error on missing file got %v", err)
+	}
+}
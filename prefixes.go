@@ -0,0 +1,136 @@
+package flaeg
+
+import (
+	"reflect"
+	"sort"
+	"strings"
+
+	"github.com/containous/flaeg/parse"
+)
+
+// commandFlags lists the flags of a command: names and shorthands linked to true for boolean flags.
+// Shorthands of the other flags take the next argument as value.
+type commandFlags struct {
+	names      map[string]bool
+	shorthands map[string]bool
+}
+
+// getCommandFlags returns the flags parsed for config, with the built-in ones: --print-config only if printConfig is true
+func getCommandFlags(config interface{}, customParsers map[reflect.Type]parse.Parser, printConfig bool) (*commandFlags, error) {
+	parsers, err := parse.LoadParsers(customParsers)
+	if err != nil {
+		return nil, err
+	}
+
+	flagMap := make(map[string]reflect.StructField)
+	if err := getTypesRecursive(reflect.ValueOf(config), flagMap, ""); err != nil {
+		return nil, err
+	}
+
+	flags := &commandFlags{
+		names:      map[string]bool{"help": true},
+		shorthands: map[string]bool{"h": true},
+	}
+	if _, ok := flagMap[printConfigFlag]; !ok && printConfig {
+		// --print-config takes its format only as --print-config=format
+		flags.names[printConfigFlag] = true
+	}
+
+	for flg, structField := range flagMap {
+		parser, err := newFieldParser(structField, parsers)
+		if err != nil {
+			continue
+		}
+
+		isBool := isBoolFlag(parser)
+		flags.names[flg] = isBool
+		if short := structField.Tag.Get("short"); len(short) == 1 {
+			flags.shorthands[short] = isBool
+		}
+		if fileFlag := secretFileFlag(flg); isSecret(structField) && flagMap[fileFlag].Type == nil {
+			flags.names[fileFlag] = false
+		}
+	}
+	return flags, nil
+}
+
+// expandFlagPrefixes replaces the long flags given by a prefix of a single flag by this flag:
+// --db.con gives --db.connectionmax64 if no other flag starts with db.con.
+// It returns an AmbiguousFlagError if many flags start with the prefix.
+// Unknown flags are left to the flag parser, and arguments after -- are not expanded.
+func expandFlagPrefixes(args []string, flags *commandFlags) ([]string, error) {
+	expanded := make([]string, 0, len(args))
+	for i := 0; i < len(args); i++ {
+		arg := args[i]
+		expanded = append(expanded, arg)
+
+		switch {
+		case arg == "--":
+			return append(expanded, args[i+1:]...), nil
+
+		case strings.HasPrefix(arg, "--"):
+			name, value := arg[2:], ""
+			if index := strings.Index(name, "="); index != -1 {
+				name, value = name[:index], name[index:]
+			}
+			name = strings.ToLower(name)
+
+			if _, ok := flags.names[name]; !ok {
+				candidates := flags.withPrefix(name)
+				if len(candidates) > 1 {
+					return nil, &AmbiguousFlagError{Flag: name, Candidates: candidates}
+				}
+				if len(candidates) == 0 {
+					continue
+				}
+				expanded[len(expanded)-1] = "--" + candidates[0] + value
+			}
+
+		case strings.HasPrefix(arg, "-") && len(arg) == 2:
+			// the next argument is the value of the shorthand flag:
+			// long flags only take their values as --flag=value
+			if isBool, ok := flags.shorthands[strings.ToLower(arg[1:])]; ok && !isBool && i+1 < len(args) {
+				i++
+				expanded = append(expanded, args[i])
+			}
+		}
+	}
+	return expanded, nil
+}
+
+// withPrefix returns the flags starting with prefix, sorted
+func (c *commandFlags) withPrefix(prefix string) []string {
+	var candidates []string
+	for name := range c.names {
+		if strings.HasPrefix(name, prefix) {
+			candidates = append(candidates, name)
+		}
+	}
+	sort.Strings(candidates)
+	return candidates
+}
+
+// findCommandByPrefix returns the command among commands whose name starts with prefix.
+// It returns an AmbiguousCommandError if many commands start with the prefix, and nil if none.
+func findCommandByPrefix(prefix string, commands []*Command) (*Command, error) {
+	var found []*Command
+	for _, command := range commands {
+		if !command.HideHelp && strings.HasPrefix(command.Name, prefix) {
+			found = append(found, command)
+		}
+	}
+
+	switch len(found) {
+	case 0:
+		return nil, nil
+	case 1:
+		return found[0], nil
+	}
+
+	var candidates []string
+	for _, command := range found {
+		candidates = append(candidates, command.Name)
+	}
+	sort.Strings(candidates)
+	return nil, &AmbiguousCommandError{Command: prefix, Candidates: candidates}
+}
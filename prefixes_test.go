@@ -0,0 +1,133 @@
+package flaeg
+
+import (
+	"errors"
+	"reflect"
+	"testing"
+)
+
+type prefixesConfiguration struct {
+	LogLevel string           `short:"l" description:"Log level"`
+	Password string           `secret:"true" description:"Password"`
+	DB       *prefixesDBInfo  `description:"Enable database"`
+	Debug    bool             `short:"d" description:"Debug mode"`
+	Ignored  map[string]int64 `description:"Without parser"`
+}
+
+type prefixesDBInfo struct {
+	ComaX           uint   `description:"Number max of connections on database"`
+	ConnectionMax64 int64  `description:"Number max of connections on database"`
+	IP              string `description:"Server ip address"`
+}
+
+func TestExpandFlagPrefixes(t *testing.T) {
+	flags, err := getCommandFlags(&prefixesConfiguration{}, nil, true)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	testCases := []struct {
+		args       []string
+		expected   []string
+		candidates []string
+	}{
+		{args: []string{"--db.co=1"}, candidates: []string{"db.comax", "db.connectionmax64"}},
+		{args: []string{"--db.com=1", "--db.con=64"}, expected: []string{"--db.comax=1", "--db.connectionmax64=64"}},
+		{args: []string{"--DB.I=1.2.3.4", "--logl=DEBUG"}, expected: []string{"--db.ip=1.2.3.4", "--loglevel=DEBUG"}},
+		{args: []string{"--db", "--deb"}, expected: []string{"--db", "--debug"}},
+		{args: []string{"--db.comx=1"}, expected: []string{"--db.comx=1"}},
+		{args: []string{"--pass=p"}, candidates: []string{"password", "password-file"}},
+		{args: []string{"--password-f=/run/secret"}, expected: []string{"--password-file=/run/secret"}},
+		{args: []string{"--print=json"}, expected: []string{"--print-config=json"}},
+		{args: []string{"--h"}, expected: []string{"--help"}},
+		{args: []string{"--ignored=a"}, expected: []string{"--ignored=a"}},
+		// long flags without value take no separate value, unlike shorthands
+		{args: []string{"--logl", "--deb"}, expected: []string{"--loglevel", "--debug"}},
+		{args: []string{"--loglevel", "--deb", "-l", "--deb", "-d", "--deb"}, expected: []string{"--loglevel", "--debug", "-l", "--deb", "-d", "--debug"}},
+		{args: []string{"--deb", "--", "--deb"}, expected: []string{"--debug", "--", "--deb"}},
+	}
+
+	for _, test := range testCases {
+		args, err := expandFlagPrefixes(test.args, flags)
+
+		if len(test.candidates) > 0 {
+			var ambiguous *AmbiguousFlagError
+			if !errors.As(err, &ambiguous) || !reflect.DeepEqual(ambiguous.Candidates, test.candidates) {
+				t.Errorf("%v: expected AmbiguousFlagError with candidates %v got %v", test.args, test.candidates, err)
+			}
+			continue
+		}
+		if err != nil {
+			t.Errorf("%v: %v", test.args, err)
+		}
+		if !reflect.DeepEqual(args, test.expected) {
+			t.Errorf("%v: expected %v got %v", test.args, test.expected, args)
+		}
+	}
+}
+
+func TestExpandFlagPrefixesPrintConfigDisabled(t *testing.T) {
+	flags, err := getCommandFlags(&prefixesConfiguration{}, nil, false)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	args, err := expandFlagPrefixes([]string{"--print=json"}, flags)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if expected := []string{"--print=json"}; !reflect.DeepEqual(args, expected) {
+		t.Errorf("expected %v got %v", expected, args)
+	}
+}
+
+func TestFlaegPrefixMatching(t *testing.T) {
+	testCases := []struct {
+		desc        string
+		args        []string
+		prefixMatch bool
+		expected    string
+		expectedErr error
+	}{
+		{desc: "command prefix", args: []string{"ver"}, prefixMatch: true, expected: "version "},
+		{desc: "command prefix and flag prefix", args: []string{"valid", "--i=1.2.3.4"}, prefixMatch: true, expected: "validate 1.2.3.4"},
+		{desc: "ambiguous flag", args: []string{"version", "--co=1"}, prefixMatch: true, expectedErr: &AmbiguousFlagError{Flag: "co", Candidates: []string{"comax", "connectionmax64"}}},
+		{desc: "ambiguous command", args: []string{"v"}, prefixMatch: true, expectedErr: &AmbiguousCommandError{Command: "v", Candidates: []string{"validate", "version"}}},
+		{desc: "hidden command", args: []string{"hid"}, prefixMatch: true, expectedErr: &UnknownCommandError{Command: "hid"}},
+		{desc: "without prefix matching", args: []string{"ver"}, expectedErr: &UnknownCommandError{Command: "ver"}},
+	}
+
+	for _, test := range testCases {
+		t.Run(test.desc, func(t *testing.T) {
+			var called string
+			newCommand := func(name string, hidden bool) *Command {
+				config := &prefixesDBInfo{}
+				return &Command{
+					Name:                  name,
+					Config:                config,
+					DefaultPointersConfig: &prefixesDBInfo{},
+					HideHelp:              hidden,
+					Run: func() error {
+						called = name + " " + config.IP
+						return nil
+					},
+				}
+			}
+
+			f := New(newCommand("root", false), test.args)
+			f.AddCommand(newCommand("version", false))
+			f.AddCommand(newCommand("validate", false))
+			f.AddCommand(newCommand("hidden", true))
+			f.SetPrefixMatching(test.prefixMatch)
+
+			err := f.Run()
+
+			if !reflect.DeepEqual(err, test.expectedErr) {
+				t.Errorf("expected error %v got %v", test.expectedErr, err)
+			}
+			if called != test.expected {
+				t.Errorf("expected %q called got %q", test.expected, called)
+			}
+		})
+	}
+}
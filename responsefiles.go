@@ -0,0 +1,131 @@
+package flaeg
+
+import (
+	"bufio"
+	"fmt"
+	"os"
+	"strings"
+)
+
+// maxResponseFileDepth limits the nesting of response files
+const maxResponseFileDepth = 10
+
+// expandResponseFiles replaces the arguments @path by the arguments read from the file path.
+// Files contain arguments separated by spaces or newlines, with shell-like quoting and # comments,
+// and may reference other response files.
+// @@arg gives the argument @arg, and arguments after -- are not expanded.
+func expandResponseFiles(args []string) ([]string, error) {
+	return expandResponseFilesDepth(args, 0)
+}
+
+func expandResponseFilesDepth(args []string, depth int) ([]string, error) {
+	var expanded []string
+	for i, arg := range args {
+		switch {
+		case arg == "--":
+			return append(expanded, args[i:]...), nil
+		case strings.HasPrefix(arg, "@@"):
+			expanded = append(expanded, arg[1:])
+		case strings.HasPrefix(arg, "@") && len(arg) > 1:
+			if depth >= maxResponseFileDepth {
+				return nil, fmt.Errorf("response file %s: nested more than %d times", arg[1:], maxResponseFileDepth)
+			}
+
+			fileArgs, err := readResponseFile(arg[1:])
+			if err != nil {
+				return nil, err
+			}
+			fileArgs, err = expandResponseFilesDepth(fileArgs, depth+1)
+			if err != nil {
+				return nil, err
+			}
+			expanded = append(expanded, fileArgs...)
+		default:
+			expanded = append(expanded, arg)
+		}
+	}
+	return expanded, nil
+}
+
+// readResponseFile returns the arguments written in the file path
+func readResponseFile(path string) ([]string, error) {
+	file, err := os.Open(path)
+	if err != nil {
+		return nil, fmt.Errorf("response file: %v", err)
+	}
+	defer file.Close()
+
+	var args []string
+	scanner := bufio.NewScanner(file)
+	for line := 1; scanner.Scan(); line++ {
+		lineArgs, err := splitShellWords(scanner.Text())
+		if err != nil {
+			return nil, fmt.Errorf("response file %s:%d: %v", path, line, err)
+		}
+		args = append(args, lineArgs...)
+	}
+	if err := scanner.Err(); err != nil {
+		return nil, fmt.Errorf("response file %s: %v", path, err)
+	}
+	return args, nil
+}
+
+// splitShellWords splits a line into words like a shell:
+// words are separated by spaces, single quotes keep their content as is,
+// double quotes and backslashes escape the next character (\" and \\ only in double quotes),
+// and # starts a comment at the beginning of a word.
+func splitShellWords(line string) ([]string, error) {
+	var words []string
+	var word strings.Builder
+	inWord := false
+
+	runes := []rune(line)
+	for i := 0; i < len(runes); i++ {
+		c := runes[i]
+		switch {
+		case c == ' ' || c == '\t' || c == '\r':
+			if inWord {
+				words = append(words, word.String())
+				word.Reset()
+				inWord = false
+			}
+		case c == '#' && !inWord:
+			return words, nil
+		case c == '\\':
+			if i+1 < len(runes) {
+				i++
+				word.WriteRune(runes[i])
+			}
+			inWord = true
+		case c == '\'':
+			i++
+			for ; i < len(runes) && runes[i] != '\''; i++ {
+				word.WriteRune(runes[i])
+			}
+			if i == len(runes) {
+				return nil, fmt.Errorf("unterminated quote ' in %q", line)
+			}
+			inWord = true
+		case c == '"':
+			i++
+			for ; i < len(runes) && runes[i] != '"'; i++ {
+				if runes[i] == '\\' && i+1 < len(runes) && (runes[i+1] == '"' || runes[i+1] == '\\') {
+					i++
+				}
+				word.WriteRune(runes[i])
+			}
+			if i == len(runes) {
+				return nil, fmt.Errorf("unterminated quote \" in %q", line)
+			}
+			inWord = true
+		default:
+			word.WriteRune(c)
+			inWord = true
+		}
+	}
+
+	if inWord {
+		words = append(words, word.String())
+	}
+	return words, nil
+}
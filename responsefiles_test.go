@@ -0,0 +1,214 @@
+package flaeg
+
+import (
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"reflect"
+	"strings"
+	"testing"
+)
+
+func TestSplitShellWords(t *testing.T) {
+	testCases := []struct {
+		desc     string
+		line     string
+		expected []string
+	}{
+		{
+			desc:     "empty",
+			line:     "",
+			expected: nil,
+		},
+		{
+			desc:     "comment",
+			line:     "  # --loglevel=DEBUG",
+			expected: nil,
+		},
+		{
+			desc:     "words",
+			line:     "--loglevel=DEBUG \t --db",
+			expected: []string{"--loglevel=DEBUG", "--db"},
+		},
+		{
+			desc:     "trailing comment",
+			line:     "--db # enable database",
+			expected: []string{"--db"},
+		},
+		{
+			desc:     "hash in word",
+			line:     "--color=#fff",
+			expected: []string{"--color=#fff"},
+		},
+		{
+			desc:     "double quotes",
+			line:     `--name="John \"JD\" Doe" "a b"`,
+			expected: []string{`--name=John "JD" Doe`, "a b"},
+		},
+		{
+			desc:     "single quotes",
+			line:     `--regexp='^\d+ "x"$'`,
+			expected: []string{`--regexp=^\d+ "x"$`},
+		},
+		{
+			desc:     "backslash",
+			line:     `a\ b c\\d`,
+			expected: []string{"a b", `c\d`},
+		},
+		{
+			desc:     "empty quotes",
+			line:     `--value= ""`,
+			expected: []string{"--value=", ""},
+		},
+	}
+
+	for _, test := range testCases {
+		test := test
+		t.Run(test.desc, func(t *testing.T) {
+			t.Parallel()
+
+			words, err := splitShellWords(test.line)
+			if err != nil {
+				t.Fatal(err)
+			}
+
+			if !reflect.DeepEqual(words, test.expected) {
+				t.Errorf("expected %q got %q", test.expected, words)
+			}
+		})
+	}
+}
+
+func TestSplitShellWordsUnterminatedQuote(t *testing.T) {
+	for _, line := range []string{`--name="John`, `--name='John`} {
+		if _, err := splitShellWords(line); err == nil {
+			t.Errorf("%s: want error got nil", line)
+		}
+	}
+}
+
+func TestExpandResponseFiles(t *testing.T) {
+	dir, err := ioutil.TempDir("", "flaeg")
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer os.RemoveAll(dir)
+
+	nested := filepath.Join(dir, "nested.txt")
+	if err := ioutil.WriteFile(nested, []byte("--owner.rate=0.5\n"), 0600); err != nil {
+		t.Fatal(err)
+	}
+	flags := filepath.Join(dir, "flags.txt")
+	content := "# database\n--db\n--db.ip=\"10.0.0.1\"\n\n@" + nested + "\n"
+	if err := ioutil.WriteFile(flags, []byte(content), 0600); err != nil {
+		t.Fatal(err)
+	}
+
+	args, err := expandResponseFiles([]string{"-l", "DEBUG", "@" + flags, "@@literal", "--", "@" + flags})
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	expected := []string{"-l", "DEBUG", "--db", "--db.ip=10.0.0.1", "--owner.rate=0.5", "@literal", "--", "@" + flags}
+	if !reflect.DeepEqual(args, expected) {
+		t.Errorf("expected %q got %q", expected, args)
+	}
+}
+
+func TestExpandResponseFilesError(t *testing.T) {
+	dir, err := ioutil.TempDir("", "flaeg")
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer os.RemoveAll(dir)
+
+	loop := filepath.Join(dir, "loop.txt")
+	if err := ioutil.WriteFile(loop, []byte("@"+loop), 0600); err != nil {
+		t.Fatal(err)
+	}
+	quote := filepath.Join(dir, "quote.txt")
+	if err := ioutil.WriteFile(quote, []byte("--db\n--db.ip='10.0.0.1\n"), 0600); err != nil {
+		t.Fatal(err)
+	}
+
+	testCases := []struct {
+		desc     string
+		args     []string
+		expected string
+	}{
+		{desc: "missing file", args: []string{"@" + filepath.Join(dir, "missing.txt")}, expected: "missing.txt"},
+		{desc: "recursion", args: []string{"@" + loop}, expected: "nested more than 10 times"},
+		{desc: "syntax", args: []string{"@" + quote}, expected: "quote.txt:2: unterminated quote"},
+	}
+
+	for _, test := range testCases {
+		test := test
+		t.Run(test.desc, func(t *testing.T) {
+			if _, err := expandResponseFiles(test.args); err == nil || !strings.Contains(err.Error(), test.expected) {
+				t.Errorf("expected error %s got %v", test.expected, err)
+			}
+		})
+	}
+}
+
+func TestFlaegResponseFile(t *testing.T) {
+	dir, err := ioutil.TempDir("", "flaeg")
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer os.RemoveAll(dir)
+
+	flags := filepath.Join(dir, "flags.txt")
+	if err := ioutil.WriteFile(flags, []byte("version\n--loglevel=INFO\n"), 0600); err != nil {
+		t.Fatal(err)
+	}
+
+	rootConfig := &Configuration{}
+	versionConfig := &Configuration{}
+	rootCmd := &Command{
+		Name:                  "flaegtest",
+		Config:                rootConfig,
+		DefaultPointersConfig: &Configuration{},
+		Run:                   func() error { return nil },
+	}
+	versionCmd := &Command{
+		Name:                  "version",
+		Config:                versionConfig,
+		DefaultPointersConfig: &Configuration{},
+		Run:                   func() error { return nil },
+	}
+
+	flaeg := New(rootCmd, []string{"@" + flags})
+	flaeg.AddCommand(versionCmd)
+	flaeg.AddParser(reflect.TypeOf([]ServerInfo{}), &sliceServerValue{})
+	flaeg.SetResponseFiles(true)
+
+	if err := flaeg.Run(); err != nil {
+		t.Fatal(err)
+	}
+
+	if versionConfig.LogLevel != "INFO" || rootConfig.LogLevel != "" {
+		t.Errorf("expected version command with loglevel INFO, got root %+v version %+v", rootConfig, versionConfig)
+	}
+}
+
+func TestFlaegResponseFilesDisabled(t *testing.T) {
+	config := &Configuration{}
+	rootCmd := &Command{
+		Name:                  "flaegtest",
+		Config:                config,
+		DefaultPointersConfig: &Configuration{},
+		Run:                   func() error { return nil },
+	}
+
+	flaeg := New(rootCmd, []string{"-l", "@debug"})
+	flaeg.AddParser(reflect.TypeOf([]ServerInfo{}), &sliceServerValue{})
+
+	if err := flaeg.Run(); err != nil {
+		t.Fatal(err)
+	}
+
+	if config.LogLevel != "@debug" {
+		t.Errorf("expected loglevel @debug, got %s", config.LogLevel)
+	}
+}
@@ -0,0 +1,72 @@
+package flaeg
+
+import (
+	"errors"
+	"fmt"
+	"io/ioutil"
+	"reflect"
+	"strings"
+
+	"github.com/containous/flaeg/parse"
+)
+
+// secretMask replaces the values of secret fields
+const secretMask = "******"
+
+// isSecret returns true if the field is tagged secret:"true".
+// Values of secret fields are masked in help and errors.
+func isSecret(structField reflect.StructField) bool {
+	return structField.Tag.Get("secret") == "true"
+}
+
+// secretFileFlag returns the flag giving the path of the file containing the value of a secret flag
+func secretFileFlag(flg string) string {
+	return flg + "-file"
+}
+
+// secretFileEnv returns the environment variable giving the path of the file containing the value of a secret flag:
+// db.password gives DB_PASSWORD_FILE
+func secretFileEnv(flg string) string {
+	return strings.ToUpper(strings.NewReplacer(".", "_", "-", "_").Replace(flg)) + "_FILE"
+}
+
+// secretParser keeps the errors of a parser instead of returning them,
+// as the flag parser would echo the secret value given
+type secretParser struct {
+	parse.Parser
+	err error
+}
+
+// Set sets the value, and keeps the error with the value masked.
+func (s *secretParser) Set(value string) error {
+	if err := s.Parser.Set(value); err != nil {
+		msg := err.Error()
+		if len(value) > 0 {
+			msg = strings.Replace(msg, value, secretMask, -1)
+		}
+		s.err = errors.New(msg)
+	}
+	return nil
+}
+
+func (s *secretParser) unwrap() parse.Parser { return s.Parser }
+
+// secretFileParser sets the value of a secret flag from the content of a file, without its trailing newlines.
+// This is the convention of secrets mounted by Docker or Kubernetes.
+type secretFileParser struct {
+	flag   string       // the secret flag
+	parser parse.Parser // the parser of the secret flag
+	path   string
+}
+
+// Set reads the file at path and sets its content as value of the secret flag.
+func (s *secretFileParser) Set(path string) error {
+	content, err := ioutil.ReadFile(path)
+	if err != nil {
+		return fmt.Errorf("cannot read --%s from file: %v", s.flag, err)
+	}
+	s.path = path
+	return s.parser.Set(strings.TrimRight(string(content), "\r\n"))
+}
+
+func (s *secretFileParser) String() string { return s.path }
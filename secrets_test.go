@@ -0,0 +1,162 @@
+package flaeg
+
+import (
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"reflect"
+	"strings"
+	"testing"
+)
+
+type secretConfiguration struct {
+	Password string `secret:"true" description:"Database password"`
+	PIN      int    `secret:"true" description:"PIN code"`
+	User     string `description:"Database user"`
+}
+
+func TestLoadSecret(t *testing.T) {
+	config := &secretConfiguration{Password: "default"}
+	if err := Load(config, &secretConfiguration{}, []string{"--password=s3cr3t", "--pin=1234"}); err != nil {
+		t.Fatal(err)
+	}
+
+	check := &secretConfiguration{Password: "s3cr3t", PIN: 1234}
+	if !reflect.DeepEqual(config, check) {
+		t.Errorf("expected %+v\ngot %+v", check, config)
+	}
+}
+
+func TestLoadSecretInvalidValue(t *testing.T) {
+	// catch stdout
+	backupStdout := os.Stdout
+	defer func() {
+		os.Stdout = backupStdout
+	}()
+	r, w, _ := os.Pipe()
+	os.Stdout = w
+
+	err := Load(&secretConfiguration{}, &secretConfiguration{}, []string{"--pin=12a4"})
+
+	if errClose := w.Close(); errClose != nil {
+		t.Fatal(errClose)
+	}
+	out, errRead := ioutil.ReadAll(r)
+	if errRead != nil {
+		t.Fatal(errRead)
+	}
+	os.Stdout = backupStdout
+
+	if err == nil || !strings.Contains(err.Error(), "invalid argument") || !strings.Contains(err.Error(), "--pin") {
+		t.Errorf("Expected error invalid argument for --pin got %v", err)
+	}
+	if strings.Contains(err.Error(), "12a4") || strings.Contains(string(out), "12a4") {
+		t.Errorf("Expected secret value not to be echoed got %v\n%s", err, out)
+	}
+}
+
+func TestPrintFlagsSecretDefault(t *testing.T) {
+	config := &secretConfiguration{Password: "hunter2", User: "admin"}
+	help := printFlagsDefaults(t, config, &secretConfiguration{})
+
+	if strings.Contains(help, "hunter2") || !strings.Contains(help, `(default "******")`) {
+		t.Errorf("Expected masked password got:\n%s", help)
+	}
+	if !strings.Contains(help, "--password-file or $PASSWORD_FILE") {
+		t.Errorf("Expected password file flag and environment variable got:\n%s", help)
+	}
+	if !strings.Contains(help, `(default "admin")`) {
+		t.Errorf("Expected user default got:\n%s", help)
+	}
+}
+
+func TestLoadSecretFromFile(t *testing.T) {
+	dir, err := ioutil.TempDir("", "flaeg")
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer os.RemoveAll(dir)
+
+	password := filepath.Join(dir, "password")
+	if err := ioutil.WriteFile(password, []byte("s3cr3t\n"), 0600); err != nil {
+		t.Fatal(err)
+	}
+	pin := filepath.Join(dir, "pin")
+	if err := ioutil.WriteFile(pin, []byte("1234"), 0600); err != nil {
+		t.Fatal(err)
+	}
+
+	if err := os.Setenv("PIN_FILE", pin); err != nil {
+		t.Fatal(err)
+	}
+	defer os.Unsetenv("PIN_FILE")
+
+	config := &secretConfiguration{}
+	if err := Load(config, &secretConfiguration{}, []string{"--password-file=" + password}); err != nil {
+		t.Fatal(err)
+	}
+
+	check := &secretConfiguration{Password: "s3cr3t", PIN: 1234}
+	if !reflect.DeepEqual(config, check) {
+		t.Errorf("expected %+v\ngot %+v", check, config)
+	}
+
+	// flags overwrite environment variables
+	config = &secretConfiguration{}
+	if err := Load(config, &secretConfiguration{}, []string{"--pin=42"}); err != nil {
+		t.Fatal(err)
+	}
+
+	check = &secretConfiguration{PIN: 42}
+	if !reflect.DeepEqual(config, check) {
+		t.Errorf("expected %+v\ngot %+v", check, config)
+	}
+}
+
+func TestLoadSecretFromFileInvalidValue(t *testing.T) {
+	dir, err := ioutil.TempDir("", "flaeg")
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer os.RemoveAll(dir)
+
+	pin := filepath.Join(dir, "pin")
+	if err := ioutil.WriteFile(pin, []byte("12a4"), 0600); err != nil {
+		t.Fatal(err)
+	}
+
+	// catch stdout
+	backupStdout := os.Stdout
+	defer func() {
+		os.Stdout = backupStdout
+	}()
+	_, w, _ := os.Pipe()
+	os.Stdout = w
+
+	err = Load(&secretConfiguration{}, &secretConfiguration{}, []string{"--pin-file=" + pin})
+	if err == nil || !strings.Contains(err.Error(), "--pin") || strings.Contains(err.Error(), "12a4") {
+		t.Errorf("Expected masked error on --pin got %v", err)
+	}
+
+	err = Load(&secretConfiguration{}, &secretConfiguration{}, []string{"--pin-file=" + filepath.Join(dir, "missing")})
+	if err == nil || !strings.Contains(err.Error(), "cannot read --pin from file") {
+		t.Errorf("Expected error on missing file got %v", err)
+	}
+}
+
+func TestSecretFileEnv(t *testing.T) {
+	testCases := []struct {
+		flag     string
+		expected string
+	}{
+		{flag: "password", expected: "PASSWORD_FILE"},
+		{flag: "db.password", expected: "DB_PASSWORD_FILE"},
+		{flag: "db.api-key", expected: "DB_API_KEY_FILE"},
+	}
+
+	for _, test := range testCases {
+		if env := secretFileEnv(test.flag); env != test.expected {
+			t.Errorf("%s: expected %s got %s", test.flag, test.expected, env)
+		}
+	}
+}
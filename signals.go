@@ -0,0 +1,82 @@
+package flaeg
+
+import (
+	"context"
+	"fmt"
+	"os"
+	"os/signal"
+	"syscall"
+	"time"
+)
+
+// notifySignals and stopSignals relay the signals received by the process, see signal.Notify
+var (
+	notifySignals = signal.Notify
+	stopSignals   = signal.Stop
+)
+
+// SignalError is returned by Run and RunContext when the command does not return
+// within the grace period after its context was canceled by a signal.
+type SignalError struct {
+	Signal      os.Signal
+	GracePeriod time.Duration
+}
+
+func (e *SignalError) Error() string {
+	return fmt.Sprintf("command not stopped %s after signal %s", e.GracePeriod, e.Signal)
+}
+
+// ExitCode returns 128 plus the number of the signal, as shells do.
+func (e *SignalError) ExitCode() int {
+	if sig, ok := e.Signal.(syscall.Signal); ok {
+		return 128 + int(sig)
+	}
+	return ExitFailure
+}
+
+// SetCancelOnSignals makes Run and RunContext cancel the context of the command when the process receives
+// one of signals (SIGINT and SIGTERM by default).
+// The command has then gracePeriod to return, else a SignalError is returned without waiting for it.
+// A gracePeriod of 0 waits for the command to return.
+func (f *Flaeg) SetCancelOnSignals(gracePeriod time.Duration, signals ...os.Signal) {
+	if len(signals) == 0 {
+		signals = []os.Signal{os.Interrupt, syscall.SIGTERM}
+	}
+	f.cancelSignals = signals
+	f.gracePeriod = gracePeriod
+}
+
+// runWithSignals calls runFunc with a context canceled when one of signals is received,
+// and returns a SignalError if runFunc does not return within gracePeriod after.
+func runWithSignals(ctx context.Context, runFunc func(ctx context.Context) error, signals []os.Signal, gracePeriod time.Duration) error {
+	ctx, cancel := context.WithCancel(ctx)
+	defer cancel()
+
+	signalChan := make(chan os.Signal, 1)
+	notifySignals(signalChan, signals...)
+	defer stopSignals(signalChan)
+
+	errChan := make(chan error, 1)
+	go func() {
+		errChan <- runFunc(ctx)
+	}()
+
+	select {
+	case err := <-errChan:
+		return err
+	case sig := <-signalChan:
+		cancel()
+		if gracePeriod <= 0 {
+			return <-errChan
+		}
+
+		timer := time.NewTimer(gracePeriod)
+		defer timer.Stop()
+		select {
+		case err := <-errChan:
+			return err
+		case <-timer.C:
+			return &SignalError{Signal: sig, GracePeriod: gracePeriod}
+		}
+	}
+}
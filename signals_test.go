@@ -0,0 +1,111 @@
+package flaeg
+
+import (
+	"context"
+	"errors"
+	"os"
+	"reflect"
+	"syscall"
+	"testing"
+	"time"
+)
+
+// fakeSignals replaces the signals relayed to the process,
+// and returns a func sending a signal and a func restoring the signals
+func fakeSignals(t *testing.T) (func(os.Signal), func()) {
+	backupNotify, backupStop := notifySignals, stopSignals
+	restore := func() {
+		notifySignals, stopSignals = backupNotify, backupStop
+	}
+
+	notified := make(chan chan<- os.Signal, 1)
+	notifySignals = func(c chan<- os.Signal, sig ...os.Signal) {
+		if !reflect.DeepEqual(sig, []os.Signal{os.Interrupt, syscall.SIGTERM}) {
+			t.Errorf("expected signals SIGINT and SIGTERM got %v", sig)
+		}
+		notified <- c
+	}
+	stopSignals = func(chan<- os.Signal) {}
+
+	return func(sig os.Signal) {
+		(<-notified) <- sig
+	}, restore
+}
+
+func TestRunContext(t *testing.T) {
+	type key struct{}
+	ctx := context.WithValue(context.Background(), key{}, "value")
+
+	var got interface{}
+	rootCmd := &Command{
+		Name:                  "root",
+		Config:                &struct{}{},
+		DefaultPointersConfig: &struct{}{},
+		Run: func() error {
+			return errors.New("run called instead of RunContext")
+		},
+		RunContext: func(ctx context.Context) error {
+			got = ctx.Value(key{})
+			return nil
+		},
+	}
+
+	if err := New(rootCmd, nil).RunContext(ctx); err != nil {
+		t.Fatal(err)
+	}
+	if got != "value" {
+		t.Errorf("expected the context given to RunContext got value %v", got)
+	}
+}
+
+func TestRunCancelOnSignals(t *testing.T) {
+	testCases := []struct {
+		desc        string
+		gracePeriod time.Duration
+		stopDelay   time.Duration
+		expectedErr error
+	}{
+		{desc: "stopped within the grace period", gracePeriod: time.Second, expectedErr: context.Canceled},
+		{desc: "without grace period", stopDelay: 50 * time.Millisecond, expectedErr: context.Canceled},
+		{desc: "not stopped within the grace period", gracePeriod: 10 * time.Millisecond, stopDelay: 200 * time.Millisecond, expectedErr: &SignalError{Signal: syscall.SIGTERM, GracePeriod: 10 * time.Millisecond}},
+	}
+
+	for _, test := range testCases {
+		t.Run(test.desc, func(t *testing.T) {
+			sendSignal, restore := fakeSignals(t)
+			defer restore()
+
+			stopped := make(chan struct{})
+			rootCmd := &Command{
+				Name:                  "root",
+				Config:                &struct{}{},
+				DefaultPointersConfig: &struct{}{},
+				RunContext: func(ctx context.Context) error {
+					<-ctx.Done()
+					time.Sleep(test.stopDelay)
+					close(stopped)
+					return ctx.Err()
+				},
+			}
+			f := New(rootCmd, nil)
+			f.SetCancelOnSignals(test.gracePeriod)
+
+			go sendSignal(syscall.SIGTERM)
+			err := f.Run()
+
+			if !reflect.DeepEqual(err, test.expectedErr) {
+				t.Errorf("expected error %v got %v", test.expectedErr, err)
+			}
+			<-stopped
+		})
+	}
+}
+
+func TestSignalErrorExitCode(t *testing.T) {
+	err := &SignalError{Signal: syscall.SIGTERM, GracePeriod: time.Second}
+
+	var exitCoder ExitCoder
+	if !errors.As(err, &exitCoder) || exitCoder.ExitCode() != 128+int(syscall.SIGTERM) {
+		t.Errorf("expected exit code %d got %v", 128+int(syscall.SIGTERM), exitCoder)
+	}
+}
@@ -0,0 +1,87 @@
+package flaeg
+
+import (
+	"sort"
+	"strings"
+)
+
+// maxSuggestions limits the number of suggestions given for an unknown flag or command
+const maxSuggestions = 3
+
+// suggest returns the candidates close to name, the closest first.
+// A candidate is close if it can be obtained from name with few edits:
+// insertions, deletions, substitutions or transpositions of characters, one for 5 characters.
+func suggest(name string, candidates []string) []string {
+	maxDistance := 1 + len(name)/5
+
+	type suggestion struct {
+		candidate string
+		distance  int
+	}
+	var suggestions []suggestion
+	for _, candidate := range candidates {
+		if distance := editDistance(strings.ToLower(name), strings.ToLower(candidate)); distance <= maxDistance {
+			suggestions = append(suggestions, suggestion{candidate: candidate, distance: distance})
+		}
+	}
+	sort.Slice(suggestions, func(i, j int) bool {
+		if suggestions[i].distance != suggestions[j].distance {
+			return suggestions[i].distance < suggestions[j].distance
+		}
+		return suggestions[i].candidate < suggestions[j].candidate
+	})
+
+	var closest []string
+	for i := 0; i < len(suggestions) && i < maxSuggestions; i++ {
+		closest = append(closest, suggestions[i].candidate)
+	}
+	return closest
+}
+
+// editDistance returns the optimal string alignment distance between a and b:
+// the number of insertions, deletions, substitutions and transpositions of adjacent characters
+// to change a into b
+func editDistance(a, b string) int {
+	ra, rb := []rune(a), []rune(b)
+
+	// d[i][j] is the distance between ra[:i] and rb[:j]
+	d := make([][]int, len(ra)+1)
+	for i := range d {
+		d[i] = make([]int, len(rb)+1)
+		d[i][0] = i
+	}
+	for j := range d[0] {
+		d[0][j] = j
+	}
+
+	for i := 1; i <= len(ra); i++ {
+		for j := 1; j <= len(rb); j++ {
+			cost := 1
+			if ra[i-1] == rb[j-1] {
+				cost = 0
+			}
+			d[i][j] = minInt(d[i-1][j]+1, d[i][j-1]+1, d[i-1][j-1]+cost)
+			if i > 1 && j > 1 && ra[i-1] == rb[j-2] && ra[i-2] == rb[j-1] {
+				d[i][j] = minInt(d[i][j], d[i-2][j-2]+1)
+			}
+		}
+	}
+	return d[len(ra)][len(rb)]
+}
+
+func minInt(first int, others ...int) int {
+	for _, other := range others {
+		if other < first {
+			first = other
+		}
+	}
+	return first
+}
+
+// didYouMean formats suggestions for an error message: ", did you mean a or b?"
+func didYouMean(prefix string, suggestions []string) string {
+	if len(suggestions) == 0 {
+		return ""
+	}
+	return ", did you mean " + prefix + strings.Join(suggestions, " or "+prefix) + "?"
+}
@@ -0,0 +1,57 @@
+package flaeg
+
+import (
+	"reflect"
+	"testing"
+)
+
+func TestEditDistance(t *testing.T) {
+	testCases := []struct {
+		a, b     string
+		expected int
+	}{
+		{a: "", b: "", expected: 0},
+		{a: "", b: "abc", expected: 3},
+		{a: "version", b: "version", expected: 0},
+		{a: "verison", b: "version", expected: 1},
+		{a: "db.comx", b: "db.comax", expected: 1},
+		{a: "kitten", b: "sitting", expected: 3},
+		{a: "ca", b: "abc", expected: 3},
+	}
+
+	for _, test := range testCases {
+		if distance := editDistance(test.a, test.b); distance != test.expected {
+			t.Errorf("%q %q: expected %d got %d", test.a, test.b, test.expected, distance)
+		}
+	}
+}
+
+func TestSuggest(t *testing.T) {
+	flags := []string{"db", "db.comax", "db.connectionmax64", "db.ip", "db.load", "db.load64", "loglevel", "timeout"}
+
+	testCases := []struct {
+		name     string
+		expected []string
+	}{
+		{name: "db.conectionmax46", expected: []string{"db.connectionmax64"}},
+		{name: "db.lod", expected: []string{"db.load"}},
+		{name: "db.loa64", expected: []string{"db.load64", "db.load"}},
+		{name: "LogLevl", expected: []string{"loglevel"}},
+		{name: "unknown"},
+	}
+
+	for _, test := range testCases {
+		if suggestions := suggest(test.name, flags); !reflect.DeepEqual(suggestions, test.expected) {
+			t.Errorf("%s: expected %v got %v", test.name, test.expected, suggestions)
+		}
+	}
+}
+
+func TestDidYouMean(t *testing.T) {
+	if msg := didYouMean("--", nil); msg != "" {
+		t.Errorf("expected no suggestion got %q", msg)
+	}
+	if msg := didYouMean("--", []string{"db.ip", "db"}); msg != ", did you mean --db.ip or --db?" {
+		t.Errorf("expected suggestions got %q", msg)
+	}
+}
@@ -0,0 +1,29 @@
+//go:build go1.18
+// +build go1.18
+
+package flaeg
+
+import "context"
+
+// NewCommand returns a command parsing flags into a new configuration of type T, with zero values as defaults,
+// and calling run with this configuration.
+func NewCommand[T any](name string, run func(ctx context.Context, config *T) error) *Command {
+	return NewCommandWithConfig(name, new(T), new(T), run)
+}
+
+// NewCommandWithConfig returns a command parsing flags into config, which contains the default values,
+// using defaultPointersConfig for the default values of pointers, and calling run with config:
+// with the context given to Flaeg.RunContext, or with context.Background() if the Run function of the command is called directly.
+func NewCommandWithConfig[T any](name string, config *T, defaultPointersConfig *T, run func(ctx context.Context, config *T) error) *Command {
+	return &Command{
+		Name:                  name,
+		Config:                config,
+		DefaultPointersConfig: defaultPointersConfig,
+		Run: func() error {
+			return run(context.Background(), config)
+		},
+		RunContext: func(ctx context.Context) error {
+			return run(ctx, config)
+		},
+	}
+}
@@ -0,0 +1,90 @@
+//go:build go1.18
+// +build go1.18
+
+package flaeg
+
+import (
+	"context"
+	"reflect"
+	"testing"
+)
+
+type typedConfiguration struct {
+	LogLevel string           `description:"Log level"`
+	Server   *typedServerInfo `description:"Enable server"`
+}
+
+type typedServerInfo struct {
+	Port int `description:"Server port"`
+}
+
+func TestNewCommand(t *testing.T) {
+	var got []typedConfiguration
+
+	// commands built in a loop get their own configuration
+	rootCmd := NewCommand("root", func(ctx context.Context, config *typedConfiguration) error {
+		got = append(got, *config)
+		return nil
+	})
+	f := New(rootCmd, []string{"sub1", "--loglevel=DEBUG"})
+	for _, name := range []string{"sub1", "sub2"} {
+		f.AddCommand(NewCommand(name, func(ctx context.Context, config *typedConfiguration) error {
+			got = append(got, *config)
+			return nil
+		}))
+	}
+
+	if err := f.Run(); err != nil {
+		t.Fatal(err)
+	}
+
+	if expected := []typedConfiguration{{LogLevel: "DEBUG"}}; !reflect.DeepEqual(got, expected) {
+		t.Errorf("expected %+v got %+v", expected, got)
+	}
+	if config := f.commands[2].Config.(*typedConfiguration); config.LogLevel != "" {
+		t.Errorf("expected sub2 configuration unchanged got %+v", config)
+	}
+}
+
+func TestNewCommandWithConfig(t *testing.T) {
+	var got *typedConfiguration
+	cmd := NewCommandWithConfig("root",
+		&typedConfiguration{LogLevel: "INFO"},
+		&typedConfiguration{Server: &typedServerInfo{Port: 8080}},
+		func(ctx context.Context, config *typedConfiguration) error {
+			got = config
+			return nil
+		})
+
+	if err := New(cmd, []string{"--server"}).Run(); err != nil {
+		t.Fatal(err)
+	}
+
+	expected := &typedConfiguration{LogLevel: "INFO", Server: &typedServerInfo{Port: 8080}}
+	if !reflect.DeepEqual(got, expected) {
+		t.Errorf("expected %+v got %+v", expected, got)
+	}
+}
+
+func TestNewCommandRun(t *testing.T) {
+	var got *typedConfiguration
+	cmd := NewCommand("root", func(ctx context.Context, config *typedConfiguration) error {
+		if ctx == nil {
+			t.Error("expected a context")
+		}
+		got = config
+		return nil
+	})
+
+	// as a library like staert: load the configuration, then call Run
+	if err := LoadWithCommand(cmd, []string{"--loglevel=DEBUG"}, nil, nil); err != nil {
+		t.Fatal(err)
+	}
+	if err := cmd.Run(); err != nil {
+		t.Fatal(err)
+	}
+
+	if got == nil || got.LogLevel != "DEBUG" {
+		t.Errorf("expected loglevel DEBUG got %+v", got)
+	}
+}